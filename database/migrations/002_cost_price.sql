@@ -0,0 +1,4 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS cost_price INT NOT NULL DEFAULT 0;
+
+-- Snapshot harga pokok per unit pada saat checkout
+ALTER TABLE transaction_details ADD COLUMN IF NOT EXISTS cost_price INT NOT NULL DEFAULT 0;
@@ -0,0 +1,14 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS track_expiry BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS product_batches (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id),
+    goods_receipt_line_id INT REFERENCES goods_receipt_lines(id),
+    batch_number VARCHAR(100),
+    expiry_date DATE NOT NULL,
+    received_quantity INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_batches_fefo ON product_batches(product_id, expiry_date) WHERE quantity > 0;
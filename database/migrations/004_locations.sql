@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL DEFAULT 'outlet', -- outlet | warehouse
+    address TEXT
+);
+
+-- Stok per produk per lokasi. products.stock tetap menyimpan total stok semua lokasi
+-- (barang yang sedang dalam perjalanan transfer ikut terhitung di total).
+CREATE TABLE IF NOT EXISTS product_stocks (
+    product_id INT NOT NULL REFERENCES products(id),
+    location_id INT NOT NULL REFERENCES locations(id),
+    quantity INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, location_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id SERIAL PRIMARY KEY,
+    from_location_id INT NOT NULL REFERENCES locations(id),
+    to_location_id INT NOT NULL REFERENCES locations(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    shipped_at TIMESTAMPTZ,
+    received_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_lines (
+    id SERIAL PRIMARY KEY,
+    transfer_id INT NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products(id),
+    quantity INT NOT NULL CHECK (quantity > 0)
+);
+
+-- Batch yang diambil dari lokasi asal saat transfer dikirim, dibuat ulang di lokasi tujuan saat diterima
+CREATE TABLE IF NOT EXISTS stock_transfer_batches (
+    id SERIAL PRIMARY KEY,
+    transfer_line_id INT NOT NULL REFERENCES stock_transfer_lines(id) ON DELETE CASCADE,
+    batch_number VARCHAR(100),
+    expiry_date DATE NOT NULL,
+    quantity INT NOT NULL
+);
+
+ALTER TABLE product_batches ADD COLUMN IF NOT EXISTS location_id INT REFERENCES locations(id);
+ALTER TABLE purchase_orders ADD COLUMN IF NOT EXISTS location_id INT REFERENCES locations(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS outlet_id INT REFERENCES locations(id);
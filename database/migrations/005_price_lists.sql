@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS price_lists (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    outlet_id INT REFERENCES locations(id),
+    customer_tier VARCHAR(50),
+    active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS price_list_items (
+    id SERIAL PRIMARY KEY,
+    price_list_id INT NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products(id),
+    min_quantity INT NOT NULL DEFAULT 1 CHECK (min_quantity > 0),
+    price INT NOT NULL CHECK (price >= 0),
+    UNIQUE (price_list_id, product_id, min_quantity)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_list_items_product ON price_list_items(product_id);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS product_price_history (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id),
+    old_price INT, -- NULL untuk harga awal saat produk dibuat
+    new_price INT NOT NULL,
+    source VARCHAR(20) NOT NULL, -- manual | scheduled | ...
+    reference_id INT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_price_history_product ON product_price_history(product_id, changed_at);
+
+CREATE TABLE IF NOT EXISTS scheduled_price_changes (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id),
+    new_price INT NOT NULL CHECK (new_price >= 0),
+    effective_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    applied_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_price_changes_due ON scheduled_price_changes(effective_at) WHERE status = 'pending';
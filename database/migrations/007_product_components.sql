@@ -0,0 +1,20 @@
+-- Resep / bill of materials: produk komposit (paket, minuman racikan) terdiri dari produk komponen
+CREATE TABLE IF NOT EXISTS product_components (
+    product_id INT NOT NULL REFERENCES products(id),
+    component_id INT NOT NULL REFERENCES products(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (product_id, component_id),
+    CHECK (product_id <> component_id)
+);
+
+-- Pemakaian komponen per transaksi, supaya konsumsi komponen bisa dilaporkan terpisah dari penjualan paket
+CREATE TABLE IF NOT EXISTS transaction_component_usage (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id),
+    bundle_product_id INT NOT NULL REFERENCES products(id),
+    component_product_id INT NOT NULL REFERENCES products(id),
+    quantity INT NOT NULL,
+    cost_price INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_component_usage_tx ON transaction_component_usage(transaction_id);
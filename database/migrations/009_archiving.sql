@@ -0,0 +1,2 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+-- Key file foto produk di media storage; thumbnail disimpan dengan key turunan (lihat ProductService)
+ALTER TABLE products ADD COLUMN IF NOT EXISTS image_key VARCHAR(255);
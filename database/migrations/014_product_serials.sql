@@ -0,0 +1,20 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS track_serial BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Pembeli dicatat di transaksi untuk keperluan garansi produk serial
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS customer_name VARCHAR(255);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS customer_phone VARCHAR(50);
+
+CREATE TABLE IF NOT EXISTS product_serials (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id),
+    serial_number VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'in_stock', -- in_stock | sold
+    goods_receipt_line_id INT REFERENCES goods_receipt_lines(id),
+    transaction_id INT REFERENCES transactions(id),
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sold_at TIMESTAMPTZ,
+    UNIQUE (product_id, serial_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_serials_serial ON product_serials(serial_number);
+CREATE INDEX IF NOT EXISTS idx_product_serials_available ON product_serials(product_id) WHERE status = 'in_stock';
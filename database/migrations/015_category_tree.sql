@@ -0,0 +1,3 @@
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id INT REFERENCES categories(id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
@@ -0,0 +1,116 @@
+-- Ledger mutasi stok untuk laporan nilai persediaan "as of" tanggal tertentu.
+-- location_id NULL = total stok (products.stock), selain itu stok per lokasi (product_stocks).
+-- Diisi otomatis oleh trigger, jadi semua jalur yang mengubah stok (checkout, receive PO,
+-- transfer, edit/import/bulk update produk) tercatat tanpa perlu diubah satu per satu.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id),
+    location_id INT REFERENCES locations(id),
+    quantity INT NOT NULL, -- selisih stok, negatif = keluar
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_created ON stock_movements(created_at);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements(product_id, location_id, created_at);
+
+-- Backfill sekali (hanya kalau ledger masih kosong) dari dokumen yang ada: penerimaan PO,
+-- penjualan (komponen untuk produk paket) dan transfer antar lokasi. Sisa selisih dengan stok
+-- sekarang (stok awal, edit manual) dicatat sebagai saldo awal bertanggal -infinity.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM stock_movements) THEN
+        RETURN;
+    END IF;
+
+    WITH sales AS (
+        -- produk biasa: stok produk itu sendiri yang berkurang
+        SELECT td.product_id, t.outlet_id AS location_id, -td.quantity AS quantity, t.created_at
+        FROM transaction_details td
+        JOIN transactions t ON td.transaction_id = t.id
+        WHERE NOT EXISTS (SELECT 1 FROM transaction_component_usage u
+                          WHERE u.transaction_id = td.transaction_id AND u.bundle_product_id = td.product_id)
+        UNION ALL
+        -- produk paket: stok komponennya yang berkurang
+        SELECT u.component_product_id, t.outlet_id, -u.quantity, t.created_at
+        FROM transaction_component_usage u
+        JOIN transactions t ON u.transaction_id = t.id
+    ), receipts AS (
+        SELECT grl.product_id, po.location_id, grl.quantity, gr.received_at AS created_at
+        FROM goods_receipt_lines grl
+        JOIN goods_receipts gr ON grl.goods_receipt_id = gr.id
+        JOIN purchase_orders po ON gr.purchase_order_id = po.id
+    ), transfers AS (
+        SELECT l.product_id, st.from_location_id AS location_id, -l.quantity AS quantity, st.shipped_at AS created_at
+        FROM stock_transfer_lines l
+        JOIN stock_transfers st ON l.transfer_id = st.id
+        WHERE st.shipped_at IS NOT NULL
+        UNION ALL
+        SELECT l.product_id, st.to_location_id, l.quantity, st.received_at
+        FROM stock_transfer_lines l
+        JOIN stock_transfers st ON l.transfer_id = st.id
+        WHERE st.received_at IS NOT NULL
+    ), documents AS (
+        -- total stok: transfer tidak mengubah total
+        SELECT product_id, NULL::int AS location_id, quantity, created_at FROM sales
+        UNION ALL
+        SELECT product_id, NULL::int, quantity, created_at FROM receipts
+        UNION ALL
+        -- stok per lokasi
+        SELECT product_id, location_id, quantity, created_at FROM sales WHERE location_id IS NOT NULL
+        UNION ALL
+        SELECT product_id, location_id, quantity, created_at FROM receipts WHERE location_id IS NOT NULL
+        UNION ALL
+        SELECT product_id, location_id, quantity, created_at FROM transfers
+    ), current_stock AS (
+        SELECT id AS product_id, NULL::int AS location_id, stock AS quantity FROM products
+        UNION ALL
+        SELECT product_id, location_id, quantity FROM product_stocks
+    ), backfill AS (
+        SELECT product_id, location_id, quantity, created_at FROM documents
+        UNION ALL
+        SELECT c.product_id, c.location_id, c.quantity - COALESCE(SUM(d.quantity), 0), '-infinity'::timestamptz
+        FROM current_stock c
+        LEFT JOIN documents d ON d.product_id = c.product_id AND d.location_id IS NOT DISTINCT FROM c.location_id
+        GROUP BY c.product_id, c.location_id, c.quantity
+    )
+    INSERT INTO stock_movements (product_id, location_id, quantity, created_at)
+    SELECT product_id, location_id, quantity, created_at
+    FROM backfill
+    WHERE quantity <> 0
+    ORDER BY created_at, product_id, location_id NULLS FIRST;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION record_product_stock_movement() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.stock <> 0 THEN
+        INSERT INTO stock_movements (product_id, quantity) VALUES (NEW.id, NEW.stock);
+    ELSIF TG_OP = 'UPDATE' AND NEW.stock <> OLD.stock THEN
+        INSERT INTO stock_movements (product_id, quantity) VALUES (NEW.id, NEW.stock - OLD.stock);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_products_stock_movement ON products;
+CREATE TRIGGER trg_products_stock_movement
+    AFTER INSERT OR UPDATE OF stock ON products
+    FOR EACH ROW EXECUTE FUNCTION record_product_stock_movement();
+
+CREATE OR REPLACE FUNCTION record_location_stock_movement() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' AND NEW.quantity <> 0 THEN
+        INSERT INTO stock_movements (product_id, location_id, quantity) VALUES (NEW.product_id, NEW.location_id, NEW.quantity);
+    ELSIF TG_OP = 'UPDATE' AND NEW.quantity <> OLD.quantity THEN
+        INSERT INTO stock_movements (product_id, location_id, quantity) VALUES (NEW.product_id, NEW.location_id, NEW.quantity - OLD.quantity);
+    ELSIF TG_OP = 'DELETE' AND OLD.quantity <> 0 THEN
+        INSERT INTO stock_movements (product_id, location_id, quantity) VALUES (OLD.product_id, OLD.location_id, -OLD.quantity);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_product_stocks_movement ON product_stocks;
+CREATE TRIGGER trg_product_stocks_movement
+    AFTER INSERT OR UPDATE OF quantity OR DELETE ON product_stocks
+    FOR EACH ROW EXECUTE FUNCTION record_location_stock_movement();
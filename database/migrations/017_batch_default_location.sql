@@ -0,0 +1,8 @@
+-- Batch yang diterima sebelum ada lokasi (location_id NULL) tidak bisa diambil checkout outlet,
+-- karena FEFO outlet hanya membaca batch di lokasi outlet itu. Pindahkan ke lokasi default:
+-- outlet dengan id terkecil, atau lokasi pertama kalau belum ada outlet.
+-- Tidak mengubah product_stocks; stok per lokasi tetap mengikuti stock opname.
+UPDATE product_batches
+SET location_id = (SELECT id FROM locations ORDER BY type <> 'outlet', id LIMIT 1)
+WHERE location_id IS NULL
+  AND EXISTS (SELECT 1 FROM locations);
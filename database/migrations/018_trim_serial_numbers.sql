@@ -0,0 +1,10 @@
+-- Serial yang tersimpan dengan spasi di awal/akhir tidak bisa dicari maupun dijual karena lookup
+-- memakai bentuk yang sudah di-trim. Rapikan, kecuali kalau bentuk trim-nya sudah ada untuk produk
+-- yang sama; kalau beberapa serial trim-nya sama, hanya yang id-nya terkecil yang dirapikan.
+UPDATE product_serials s
+SET serial_number = btrim(s.serial_number)
+WHERE s.serial_number <> btrim(s.serial_number)
+  AND NOT EXISTS (SELECT 1 FROM product_serials d
+                  WHERE d.product_id = s.product_id AND d.serial_number = btrim(s.serial_number))
+  AND s.id = (SELECT MIN(x.id) FROM product_serials x
+              WHERE x.product_id = s.product_id AND btrim(x.serial_number) = btrim(s.serial_number));
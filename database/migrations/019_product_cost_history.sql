@@ -0,0 +1,72 @@
+-- Riwayat HPP rata-rata (products.cost_price) supaya nilai persediaan "as of" tanggal lampau
+-- memakai HPP yang berlaku saat itu, sama dengan yang dipakai checkout untuk COGS.
+CREATE TABLE IF NOT EXISTS product_cost_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(id),
+    cost_price INT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_cost_history_product ON product_cost_history(product_id, changed_at);
+
+-- Backfill sekali: putar ulang rumus rata-rata ReceiveStock untuk setiap penerimaan PO, dengan stok
+-- sebelum penerimaan diambil dari stock_movements. HPP stok awal sebelum penerimaan pertama tidak
+-- diketahui, jadi dianggap sama dengan harga beli penerimaan pertama. Kalau hasil akhirnya berbeda
+-- dengan cost_price sekarang (edit manual/import), nilai sekarang dicatat sebagai perubahan terakhir.
+DO $$
+DECLARE
+    prod RECORD;
+    rcpt RECORD;
+    cost NUMERIC;
+    stock_before INT;
+BEGIN
+    IF EXISTS (SELECT 1 FROM product_cost_history) THEN
+        RETURN;
+    END IF;
+
+    FOR prod IN SELECT id, cost_price FROM products ORDER BY id LOOP
+        cost := NULL;
+        FOR rcpt IN
+            SELECT grl.quantity, grl.unit_cost, gr.received_at
+            FROM goods_receipt_lines grl
+            JOIN goods_receipts gr ON grl.goods_receipt_id = gr.id
+            WHERE grl.product_id = prod.id
+            ORDER BY gr.received_at, grl.id
+        LOOP
+            SELECT COALESCE(SUM(quantity), 0) INTO stock_before
+            FROM stock_movements
+            WHERE product_id = prod.id AND location_id IS NULL AND created_at < rcpt.received_at;
+
+            IF cost IS NULL THEN
+                cost := rcpt.unit_cost;
+            END IF;
+            cost := ROUND((GREATEST(stock_before, 0) * cost + rcpt.quantity::numeric * rcpt.unit_cost)
+                          / (GREATEST(stock_before, 0) + rcpt.quantity));
+
+            INSERT INTO product_cost_history (product_id, cost_price, changed_at)
+            VALUES (prod.id, cost, rcpt.received_at);
+        END LOOP;
+
+        IF cost IS NULL THEN
+            INSERT INTO product_cost_history (product_id, cost_price, changed_at)
+            VALUES (prod.id, prod.cost_price, '-infinity');
+        ELSIF cost <> prod.cost_price THEN
+            INSERT INTO product_cost_history (product_id, cost_price) VALUES (prod.id, prod.cost_price);
+        END IF;
+    END LOOP;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION record_product_cost_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.cost_price <> OLD.cost_price THEN
+        INSERT INTO product_cost_history (product_id, cost_price) VALUES (NEW.id, NEW.cost_price);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_products_cost_history ON products;
+CREATE TRIGGER trg_products_cost_history
+    AFTER INSERT OR UPDATE OF cost_price ON products
+    FOR EACH ROW EXECUTE FUNCTION record_product_cost_change();
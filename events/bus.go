@@ -0,0 +1,62 @@
+// Package events - event bus in-process untuk mengirim event penjualan ke dashboard (SSE)
+package events
+
+import (
+	"kasir/models"
+	"sync"
+)
+
+// subscriberBuffer - jumlah event yang boleh antre per subscriber sebelum event berikutnya dibuang
+const subscriberBuffer = 16
+
+// Bus - publish/subscribe DashboardEvent antar goroutine dalam satu proses.
+// Publish tidak pernah menunggu subscriber: client yang lambat kehilangan event,
+// tapi karena setiap event membawa total berjalan, event berikutnya tetap benar.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[chan models.DashboardEvent]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[chan models.DashboardEvent]struct{})}
+}
+
+// Subscribe - daftar subscriber baru; panggil unsubscribe setelah selesai supaya channel ditutup
+func (b *Bus) Subscribe() (events <-chan models.DashboardEvent, unsubscribe func()) {
+	ch := make(chan models.DashboardEvent, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// HasSubscribers - true kalau ada client yang sedang berlangganan; publisher bisa melewati
+// perhitungan payload yang mahal kalau tidak ada yang mendengarkan
+func (b *Bus) HasSubscribers() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers) > 0
+}
+
+// Publish - kirim event ke semua subscriber tanpa blocking
+func (b *Bus) Publish(event models.DashboardEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
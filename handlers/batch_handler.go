@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir/services"
+	"net/http"
+	"strconv"
+)
+
+// Default jendela "expiring soon" kalau ?days= tidak diisi
+const defaultExpiringDays = 30
+
+type BatchHandler struct {
+	service *services.BatchService
+}
+
+func NewBatchHandler(service *services.BatchService) *BatchHandler {
+	return &BatchHandler{service: service}
+}
+
+// HandleBatches - GET /api/batches?product_id=
+func (h *BatchHandler) HandleBatches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	productID, err := strconv.Atoi(r.URL.Query().Get("product_id"))
+	if err != nil || productID <= 0 {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	batches, err := h.service.GetByProduct(productID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batches)
+}
+
+// HandleExpiring - GET /api/batches/expiring?days=
+func (h *BatchHandler) HandleExpiring(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days := defaultExpiringDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	batches, err := h.service.GetExpiring(days)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batches)
+}
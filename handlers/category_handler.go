@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"kasir/models"
 	"kasir/services"
 	"net/http"
@@ -29,9 +30,10 @@ func (h *CategoryHandler) HandleCategories(w http.ResponseWriter, r *http.Reques
 	}
 }
 
-// GetAll - GET /api/categories
+// GetAll - GET /api/categories?include_archived=true
 func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	categories, err := h.service.GetAll()
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+	categories, err := h.service.GetAll(includeArchived)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -41,6 +43,19 @@ func (h *CategoryHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(categories)
 }
 
+// GetTree - GET /api/categories/tree?include_archived=true
+func (h *CategoryHandler) GetTree(w http.ResponseWriter, r *http.Request) {
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+	tree, err := h.service.GetTree(includeArchived)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
 // Create - POST /api/categories
 func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var category models.Category
@@ -61,8 +76,22 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(category)
 }
 
-// HandleCategoryByID - GET/PUT/DELETE /api/categories/{id}
+// HandleCategoryByID - GET/PUT/DELETE /api/categories/{id} dan POST /api/categories/{id}/restore
 func (h *CategoryHandler) HandleCategoryByID(w http.ResponseWriter, r *http.Request) {
+	if strings.TrimPrefix(r.URL.Path, "/api/categories/") == "tree" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetTree(w, r)
+		return
+	}
+
+	if idStr, action, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/categories/"), "/"); found {
+		h.handleCategoryAction(w, r, idStr, action)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		h.GetByID(w, r)
@@ -103,15 +132,14 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var category models.Category
-	err = json.NewDecoder(r.Body).Decode(&category)
+	var update models.CategoryUpdate
+	err = json.NewDecoder(r.Body).Decode(&update)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	category.ID = id
-	err = h.service.Update(&category)
+	category, err := h.service.Update(id, update)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -121,7 +149,8 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(category)
 }
 
-// Delete - DELETE /api/categories/{id}
+// Delete - DELETE /api/categories/{id}?reassign_to={id}|uncategorize=true, kategori diarsipkan (soft delete).
+// Kalau kategori masih punya produk dan tidak ada opsi yang dipilih, response 409 berisi jumlah produknya.
 func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/categories/")
 	id, err := strconv.Atoi(idStr)
@@ -130,14 +159,97 @@ func (h *CategoryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Delete(id)
+	opts := models.CategoryArchiveOptions{
+		Uncategorize: r.URL.Query().Get("uncategorize") == "true",
+	}
+	opts.ReassignTo, err = queryInt(r, "reassign_to", 0)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	affected, err := h.service.Archive(id, opts)
+	var inUse *models.CategoryInUseError
+	if errors.As(err, &inUse) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":         inUse.Error(),
+			"product_count": inUse.ProductCount,
+		})
+		return
+	}
+	if err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Category deleted successfully",
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":           "Category archived successfully",
+		"affected_products": affected,
 	})
 }
+
+// handleCategoryAction - sub-resource kategori, mis. /api/categories/{id}/restore
+func (h *CategoryHandler) handleCategoryAction(w http.ResponseWriter, r *http.Request, idStr, action string) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case "restore":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Restore(w, r, id)
+	case "merge":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Merge(w, r, id)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Restore - POST /api/categories/{id}/restore
+func (h *CategoryHandler) Restore(w http.ResponseWriter, r *http.Request, id int) {
+	err := h.service.Restore(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	category, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(category)
+}
+
+// Merge - POST /api/categories/{id}/merge?dry_run=true, body {"target_id": ...}
+func (h *CategoryHandler) Merge(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.CategoryMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	result, err := h.service.Merge(id, req.TargetID, dryRun)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"kasir/models"
+	"kasir/services"
+	"net/http"
+	"time"
+)
+
+// Interval komentar keep-alive supaya koneksi SSE tidak diputus proxy saat tidak ada penjualan
+const dashboardKeepAlive = 15 * time.Second
+
+type DashboardHandler struct {
+	service *services.TransactionService
+}
+
+func NewDashboardHandler(service *services.TransactionService) *DashboardHandler {
+	return &DashboardHandler{service: service}
+}
+
+// HandleStream - GET /api/dashboard/stream (Server-Sent Events).
+// Event pertama "snapshot" berisi total hari ini, lalu "sale" setiap ada transaksi baru.
+func (h *DashboardHandler) HandleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe sebelum snapshot supaya penjualan di antaranya tidak terlewat
+	events, unsubscribe := h.service.SubscribeDashboard()
+	defer unsubscribe()
+
+	now := time.Now()
+	totals, err := h.service.DailyTotals(now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	writeSSE(w, models.DashboardEvent{Type: models.DashboardEventSnapshot, Totals: totals, Time: now})
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(dashboardKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			writeSSE(w, event)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSE - satu event SSE; id = id transaksi supaya client bisa mendeteksi event yang terlewat
+func writeSSE(w http.ResponseWriter, event models.DashboardEvent) {
+	data, _ := json.Marshal(event)
+	if event.Transaction != nil {
+		fmt.Fprintf(w, "id: %d\n", event.Transaction.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
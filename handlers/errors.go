@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// writeServiceError - petakan error dari service ke status HTTP:
+// data tidak ditemukan -> 404, pelanggaran aturan bisnis -> 400, selain itu -> 500
+func writeServiceError(w http.ResponseWriter, err error) {
+	msg := err.Error()
+	if strings.Contains(msg, "tidak ditemukan") {
+		http.Error(w, msg, http.StatusNotFound)
+		return
+	}
+
+	for _, marker := range []string{"cannot", "invalid", "not found", "over receipt", "required", "insufficient stock"} {
+		if strings.Contains(msg, marker) {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+	}
+
+	http.Error(w, msg, http.StatusInternalServerError)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir/models"
+	"kasir/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type LocationHandler struct {
+	service *services.LocationService
+}
+
+func NewLocationHandler(service *services.LocationService) *LocationHandler {
+	return &LocationHandler{service: service}
+}
+
+// HandleLocations - GET/POST /api/locations
+func (h *LocationHandler) HandleLocations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleLocationByID - GET/PUT /api/locations/{id} dan GET/PUT /api/locations/{id}/stock
+func (h *LocationHandler) HandleLocationByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/locations/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid location ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "stock" && r.Method == http.MethodGet:
+		h.GetStock(w, r, id)
+	case action == "stock" && r.Method == http.MethodPut:
+		h.SetStock(w, r, id)
+	case action != "" && action != "stock":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/locations
+func (h *LocationHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.service.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(locations)
+}
+
+// Create - POST /api/locations
+func (h *LocationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var location models.Location
+	err := json.NewDecoder(r.Body).Decode(&location)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&location)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(location)
+}
+
+// GetByID - GET /api/locations/{id}
+func (h *LocationHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	location, err := h.service.GetByID(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(location)
+}
+
+// Update - PUT /api/locations/{id}
+func (h *LocationHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var location models.Location
+	err := json.NewDecoder(r.Body).Decode(&location)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	location.ID = id
+	err = h.service.Update(&location)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(location)
+}
+
+// GetStock - GET /api/locations/{id}/stock
+func (h *LocationHandler) GetStock(w http.ResponseWriter, r *http.Request, id int) {
+	stocks, err := h.service.GetStock(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stocks)
+}
+
+// SetStock - PUT /api/locations/{id}/stock (stock opname)
+func (h *LocationHandler) SetStock(w http.ResponseWriter, r *http.Request, id int) {
+	var stocks []models.LocationStock
+	err := json.NewDecoder(r.Body).Decode(&stocks)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.SetStock(id, stocks)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	h.GetStock(w, r, id)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir/models"
+	"kasir/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type PriceChangeHandler struct {
+	service *services.PriceChangeService
+}
+
+func NewPriceChangeHandler(service *services.PriceChangeService) *PriceChangeHandler {
+	return &PriceChangeHandler{service: service}
+}
+
+// HandlePriceChanges - GET/POST /api/price-changes
+func (h *PriceChangeHandler) HandlePriceChanges(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandlePriceChangeByID - GET/DELETE /api/price-changes/{id}
+func (h *PriceChangeHandler) HandlePriceChangeByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodDelete:
+		h.Cancel(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/price-changes?status=&product_id=
+func (h *PriceChangeHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+
+	productID := 0
+	if productStr := r.URL.Query().Get("product_id"); productStr != "" {
+		id, err := strconv.Atoi(productStr)
+		if err != nil || id <= 0 {
+			http.Error(w, "Invalid product ID", http.StatusBadRequest)
+			return
+		}
+		productID = id
+	}
+
+	changes, err := h.service.GetAll(status, productID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+// Create - POST /api/price-changes
+func (h *PriceChangeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var change models.ScheduledPriceChange
+	err := json.NewDecoder(r.Body).Decode(&change)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&change)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(change)
+}
+
+// GetByID - GET /api/price-changes/{id}
+func (h *PriceChangeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/price-changes/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid price change ID", http.StatusBadRequest)
+		return
+	}
+
+	change, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(change)
+}
+
+// Cancel - DELETE /api/price-changes/{id}
+func (h *PriceChangeHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/price-changes/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid price change ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Cancel(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Price change cancelled successfully",
+	})
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir/models"
+	"kasir/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type PriceListHandler struct {
+	service *services.PriceListService
+}
+
+func NewPriceListHandler(service *services.PriceListService) *PriceListHandler {
+	return &PriceListHandler{service: service}
+}
+
+// HandlePriceLists - GET /api/price-lists (GET all) atau POST /api/price-lists (create)
+func (h *PriceListHandler) HandlePriceLists(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/price-lists
+func (h *PriceListHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	priceLists, err := h.service.GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(priceLists)
+}
+
+// Create - POST /api/price-lists
+func (h *PriceListHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var priceList models.PriceList
+	err := json.NewDecoder(r.Body).Decode(&priceList)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&priceList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(priceList)
+}
+
+// HandlePriceListByID - GET/PUT/DELETE /api/price-lists/{id}
+func (h *PriceListHandler) HandlePriceListByID(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetByID(w, r)
+	case http.MethodPut:
+		h.Update(w, r)
+	case http.MethodDelete:
+		h.Delete(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetByID - GET /api/price-lists/{id}
+func (h *PriceListHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/price-lists/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid price list ID", http.StatusBadRequest)
+		return
+	}
+
+	priceList, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(priceList)
+}
+
+// Update - PUT /api/price-lists/{id}
+func (h *PriceListHandler) Update(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/price-lists/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid price list ID", http.StatusBadRequest)
+		return
+	}
+
+	var priceList models.PriceList
+	err = json.NewDecoder(r.Body).Decode(&priceList)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	priceList.ID = id
+	err = h.service.Update(&priceList)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(priceList)
+}
+
+// Delete - DELETE /api/price-lists/{id}
+func (h *PriceListHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimPrefix(r.URL.Path, "/api/price-lists/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid price list ID", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Delete(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Price list deleted successfully",
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"kasir/models"
 	"kasir/services"
 	"net/http"
@@ -31,15 +32,65 @@ func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request)
 
 // get all products without category
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	name := r.URL.Query().Get("name")
-	products, err := h.service.GetAll(name)
+	q := r.URL.Query()
+	filter := models.ProductFilter{
+		Name:        q.Get("name"),
+		StockStatus: q.Get("stock_status"),
+		Archived:    q.Get("archived"),
+		Sort:        q.Get("sort"),
+		Order:       q.Get("order"),
+	}
+
+	// include_archived=true tetap didukung sebagai alias archived=include
+	if filter.Archived == "" && q.Get("include_archived") == "true" {
+		filter.Archived = models.ArchivedInclude
+	}
+
+	var err error
+	for _, param := range []struct {
+		key  string
+		dest *int
+		def  int
+	}{
+		{"outlet_id", &filter.OutletID, 0},
+		{"category_id", &filter.CategoryID, 0},
+		{"page", &filter.Page, 1},
+		{"page_size", &filter.PageSize, 0},
+	} {
+		*param.dest, err = queryInt(r, param.key, param.def)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for _, param := range []struct {
+		key  string
+		dest **int
+	}{
+		{"min_price", &filter.MinPrice},
+		{"max_price", &filter.MaxPrice},
+	} {
+		value := q.Get(param.key)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid "+param.key, http.StatusBadRequest)
+			return
+		}
+		*param.dest = &n
+	}
+
+	page, err := h.service.GetAll(filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(products)
+	json.NewEncoder(w).Encode(page)
 }
 
 func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -63,6 +114,50 @@ func (h *ProductHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // HandleProductByID - GET/PUT/DELETE /api/produk/{id}
 func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
+	// Route koleksi yang berbagi prefix /api/produk/
+	switch strings.TrimPrefix(r.URL.Path, "/api/produk/") {
+	case "low-stock":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetLowStock(w, r)
+		return
+	case "search":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Search(w, r)
+		return
+	case "import":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Import(w, r)
+		return
+	case "export":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Export(w, r)
+		return
+	case "bulk-update":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.BulkUpdate(w, r)
+		return
+	}
+
+	if idStr, action, found := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/produk/"), "/"); found {
+		h.handleProductAction(w, r, idStr, action)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		h.GetByID(w, r)
@@ -75,7 +170,7 @@ func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Reques
 	}
 }
 
-// GetByID - GET /api/produk/{id}
+// GetByID - GET /api/produk/{id}?outlet_id=&customer_tier=&quantity=
 func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
 	id, err := strconv.Atoi(idStr)
@@ -84,7 +179,13 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	product, err := h.service.GetByID(id)
+	pc, err := parsePriceContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.GetByID(id, pc)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -102,15 +203,14 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var product models.Product
-	err = json.NewDecoder(r.Body).Decode(&product)
+	var update models.ProductUpdate
+	err = json.NewDecoder(r.Body).Decode(&update)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	product.ID = id
-	err = h.service.Update(&product)
+	product, err := h.service.Update(id, update)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -120,7 +220,7 @@ func (h *ProductHandler) Update(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(product)
 }
 
-// Delete - DELETE /api/produk/{id}
+// Delete - DELETE /api/produk/{id}, produk diarsipkan (soft delete)
 func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimPrefix(r.URL.Path, "/api/produk/")
 	id, err := strconv.Atoi(idStr)
@@ -129,14 +229,240 @@ func (h *ProductHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.service.Delete(id)
+	err = h.service.Archive(id)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Product deleted successfully",
+		"message": "Product archived successfully",
 	})
 }
+
+// BulkUpdate - POST /api/produk/bulk-update?dry_run=true
+func (h *ProductHandler) BulkUpdate(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	result, err := h.service.BulkUpdate(req, dryRun)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleProductAction - sub-resource produk, mis. /api/produk/{id}/purchases, /price-history, /components
+func (h *ProductHandler) handleProductAction(w http.ResponseWriter, r *http.Request, idStr, action string) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	switch action {
+	case "purchases":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetPurchaseHistory(w, r, id)
+	case "restore":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Restore(w, r, id)
+	case "price-history":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetPriceHistory(w, r, id)
+	case "components":
+		switch r.Method {
+		case http.MethodGet:
+			h.GetComponents(w, r, id)
+		case http.MethodPut:
+			h.SetComponents(w, r, id)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	case "image":
+		switch r.Method {
+		case http.MethodPut, http.MethodPost:
+			h.UploadImage(w, r, id)
+		case http.MethodDelete:
+			h.DeleteImage(w, r, id)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Restore - POST /api/produk/{id}/restore
+func (h *ProductHandler) Restore(w http.ResponseWriter, r *http.Request, id int) {
+	err := h.service.Restore(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	product, err := h.service.GetByID(id, models.PriceContext{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// GetPurchaseHistory - GET /api/produk/{id}/purchases
+func (h *ProductHandler) GetPurchaseHistory(w http.ResponseWriter, r *http.Request, id int) {
+	history, err := h.service.GetPurchaseHistory(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetPriceHistory - GET /api/produk/{id}/price-history
+func (h *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request, id int) {
+	history, err := h.service.GetPriceHistory(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GetComponents - GET /api/produk/{id}/components
+func (h *ProductHandler) GetComponents(w http.ResponseWriter, r *http.Request, id int) {
+	components, err := h.service.GetComponents(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(components)
+}
+
+// SetComponents - PUT /api/produk/{id}/components, body berisi resep lengkap (array kosong = bukan komposit)
+func (h *ProductHandler) SetComponents(w http.ResponseWriter, r *http.Request, id int) {
+	var components []models.ProductComponent
+	err := json.NewDecoder(r.Body).Decode(&components)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.SetComponents(id, components)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	h.GetComponents(w, r, id)
+}
+
+// Search - GET /api/produk/search?q=&limit=20
+func (h *ProductHandler) Search(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r, "limit", 20)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit = min(limit, 100)
+
+	hits, err := h.service.Search(r.URL.Query().Get("q"), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hits)
+}
+
+// GetLowStock - GET /api/produk/low-stock
+func (h *ProductHandler) GetLowStock(w http.ResponseWriter, r *http.Request) {
+	products, err := h.service.GetLowStock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(products)
+}
+
+// ReorderSuggestions - GET /api/report/reorder?days=30&cover_days=14
+func (h *ProductHandler) ReorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days, err := queryInt(r, "days", 30)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	coverDays, err := queryInt(r, "cover_days", 14)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	suggestions, err := h.service.GetReorderSuggestions(days, coverDays)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(suggestions)
+}
+
+// parsePriceContext - baca ?outlet_id=, ?customer_tier= dan ?quantity= untuk resolusi harga
+func parsePriceContext(r *http.Request) (models.PriceContext, error) {
+	pc := models.PriceContext{
+		CustomerTier: r.URL.Query().Get("customer_tier"),
+		Quantity:     1,
+	}
+
+	if outletStr := r.URL.Query().Get("outlet_id"); outletStr != "" {
+		outletID, err := strconv.Atoi(outletStr)
+		if err != nil || outletID <= 0 {
+			return pc, errors.New("Invalid outlet ID")
+		}
+		pc.OutletID = outletID
+	}
+
+	if quantityStr := r.URL.Query().Get("quantity"); quantityStr != "" {
+		quantity, err := strconv.Atoi(quantityStr)
+		if err != nil || quantity <= 0 {
+			return pc, errors.New("Invalid quantity")
+		}
+		pc.Quantity = quantity
+	}
+
+	return pc, nil
+}
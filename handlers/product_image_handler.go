@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const maxImageSize = 5 << 20 // 5 MB
+
+// UploadImage - PUT /api/produk/{id}/image, file di multipart field "image" atau langsung sebagai body
+func (h *ProductHandler) UploadImage(w http.ResponseWriter, r *http.Request, id int) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImageSize)
+
+	var data []byte
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, ferr := r.FormFile("image")
+		if ferr != nil {
+			http.Error(w, "Invalid image upload", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		data, err = io.ReadAll(file)
+	} else {
+		data, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		http.Error(w, "Invalid image upload", http.StatusBadRequest)
+		return
+	}
+
+	product, err := h.service.SetImage(id, data)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(product)
+}
+
+// DeleteImage - DELETE /api/produk/{id}/image
+func (h *ProductHandler) DeleteImage(w http.ResponseWriter, r *http.Request, id int) {
+	if err := h.service.DeleteImage(id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Product image deleted successfully",
+	})
+}
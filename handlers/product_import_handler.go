@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"kasir/spreadsheet"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+const maxImportSize = 10 << 20 // 10 MB
+
+// Import - POST /api/produk/import?dry_run=true&format=csv|xlsx
+// File dikirim sebagai multipart field "file" atau langsung sebagai body.
+// Format diambil dari ?format=, ekstensi nama file, lalu Content-Type; default csv.
+func (h *ProductHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	var data []byte
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, ferr := r.FormFile("file")
+		if ferr != nil {
+			http.Error(w, "Invalid file upload", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		}
+		data, err = io.ReadAll(file)
+	} else {
+		if format == "" && strings.Contains(r.Header.Get("Content-Type"), "spreadsheetml") {
+			format = "xlsx"
+		}
+		data, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var records [][]string
+	switch format {
+	case "", "csv":
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+		records, err = reader.ReadAll()
+	case "xlsx":
+		records, err = spreadsheet.ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	default:
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid %s file: %v", format, err), http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	report, err := h.service.Import(records, dryRun)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(report.Errors) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// Export - GET /api/produk/export?format=csv|xlsx, seluruh katalog aktif dengan kolom yang sama dengan import
+func (h *ProductHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		http.Error(w, "Invalid format", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := h.service.ExportCatalogue()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="products.`+format+`"`)
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		spreadsheet.WriteXLSX(w, "Products", rows)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	cw := csv.NewWriter(w)
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = fmt.Sprint(value)
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+}
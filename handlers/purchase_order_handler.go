@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir/models"
+	"kasir/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type PurchaseOrderHandler struct {
+	service *services.PurchaseOrderService
+}
+
+func NewPurchaseOrderHandler(service *services.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{service: service}
+}
+
+// HandlePurchaseOrders - GET/POST /api/purchase-orders
+func (h *PurchaseOrderHandler) HandlePurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandlePurchaseOrderByID - GET/PUT /api/purchase-orders/{id}
+// dan POST /api/purchase-orders/{id}/send, /cancel, /receive
+func (h *PurchaseOrderHandler) HandlePurchaseOrderByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/purchase-orders/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid purchase order ID", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetByID(w, r, id)
+	case action == "" && r.Method == http.MethodPut:
+		h.Update(w, r, id)
+	case action == "send" && r.Method == http.MethodPost:
+		h.changeStatus(w, id, h.service.Send)
+	case action == "cancel" && r.Method == http.MethodPost:
+		h.changeStatus(w, id, h.service.Cancel)
+	case action == "receive" && r.Method == http.MethodPost:
+		h.Receive(w, r, id)
+	case action != "" && action != "send" && action != "cancel" && action != "receive":
+		http.NotFound(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll - GET /api/purchase-orders?status=
+func (h *PurchaseOrderHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	orders, err := h.service.GetAll(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+// Create - POST /api/purchase-orders
+func (h *PurchaseOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var po models.PurchaseOrder
+	err := json.NewDecoder(r.Body).Decode(&po)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&po)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(po)
+}
+
+// GetByID - GET /api/purchase-orders/{id}
+func (h *PurchaseOrderHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	po, err := h.service.GetByID(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(po)
+}
+
+// Update - PUT /api/purchase-orders/{id}
+func (h *PurchaseOrderHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var po models.PurchaseOrder
+	err := json.NewDecoder(r.Body).Decode(&po)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	po.ID = id
+	err = h.service.Update(&po)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(po)
+}
+
+// Receive - POST /api/purchase-orders/{id}/receive
+func (h *PurchaseOrderHandler) Receive(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.ReceiveRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	receipt, err := h.service.Receive(id, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(receipt)
+}
+
+func (h *PurchaseOrderHandler) changeStatus(w http.ResponseWriter, id int, change func(int) error) {
+	if err := change(id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	po, err := h.service.GetByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(po)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"kasir/pdf"
+	"kasir/spreadsheet"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Format response laporan
+const (
+	reportFormatJSON = "json"
+	reportFormatCSV  = "csv"
+	reportFormatXLSX = "xlsx"
+	reportFormatPDF  = "pdf"
+)
+
+// reportFormat - query param format, default json
+func reportFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		return reportFormatJSON, nil
+	case reportFormatJSON, reportFormatCSV, reportFormatXLSX, reportFormatPDF:
+		return format, nil
+	}
+	return "", fmt.Errorf("Invalid format")
+}
+
+// ReportExporter - tulis laporan sebagai file CSV, XLSX atau PDF.
+// PDF diberi header nama toko, judul dan periode; CSV/XLSX hanya berisi data.
+type ReportExporter struct {
+	storeName string
+}
+
+func NewReportExporter(storeName string) *ReportExporter {
+	return &ReportExporter{storeName: storeName}
+}
+
+// reportSection - satu tabel laporan; laporan ringkasan terdiri dari beberapa section
+type reportSection struct {
+	title   string
+	columns []string
+	rows    [][]interface{}
+}
+
+type reportDocument struct {
+	filename  string // tanpa ekstensi
+	title     string
+	startDate string
+	endDate   string
+	period    string // keterangan periode custom di header PDF, menggantikan start/end date
+	sections  []reportSection
+}
+
+// Write - tulis dokumen dalam format csv/xlsx/pdf. File dirender ke buffer dulu supaya kalau gagal
+// client menerima 500, bukan file yang terpotong dengan status 200.
+func (e *ReportExporter) Write(w http.ResponseWriter, format string, doc reportDocument) {
+	var buf bytes.Buffer
+	if err := e.render(&buf, format, doc); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setAttachment(w, format, doc.filename)
+	buf.WriteTo(w)
+}
+
+// render - isi file laporan. Section diberi baris judul kalau lebih dari satu.
+func (e *ReportExporter) render(w io.Writer, format string, doc reportDocument) error {
+	if format == reportFormatPDF {
+		period := doc.period
+		if period == "" {
+			period = periodLabel(doc.startDate, doc.endDate)
+		}
+		document := pdf.Document{Header: []string{e.storeName, doc.title, period}}
+		for _, section := range doc.sections {
+			table := pdf.Table{Title: section.title, Columns: section.columns}
+			for _, row := range section.rows {
+				table.Rows = append(table.Rows, cellStrings(row))
+			}
+			document.Tables = append(document.Tables, table)
+		}
+		return document.Write(w)
+	}
+
+	var rows [][]interface{}
+	for i, section := range doc.sections {
+		if len(doc.sections) > 1 {
+			if i > 0 {
+				rows = append(rows, []interface{}{})
+			}
+			rows = append(rows, []interface{}{section.title})
+		}
+		header := make([]interface{}, len(section.columns))
+		for j, c := range section.columns {
+			header[j] = c
+		}
+		rows = append(rows, header)
+		rows = append(rows, section.rows...)
+	}
+
+	if format == reportFormatXLSX {
+		return spreadsheet.WriteXLSX(w, doc.title, rows)
+	}
+
+	cw := csv.NewWriter(w)
+	for _, row := range rows {
+		if err := cw.Write(cellStrings(row)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// setAttachment - header Content-Type dan nama file download
+func setAttachment(w http.ResponseWriter, format, filename string) {
+	contentTypes := map[string]string{
+		reportFormatCSV:  "text/csv; charset=utf-8",
+		reportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		reportFormatPDF:  "application/pdf",
+	}
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.`+format+`"`)
+}
+
+func periodLabel(startDate, endDate string) string {
+	switch {
+	case startDate == "" && endDate == "":
+		return "Period: all time"
+	case startDate == "":
+		return "Period: until " + endDate
+	case endDate == "":
+		return "Period: from " + startDate
+	}
+	return "Period: " + startDate + " to " + endDate
+}
+
+func cellStrings(row []interface{}) []string {
+	cells := make([]string, len(row))
+	for i, value := range row {
+		cells[i] = cellString(value)
+	}
+	return cells
+}
+
+// cellString - format nilai sel yang sama untuk CSV dan PDF
+func cellString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprint(value)
+}
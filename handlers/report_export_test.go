@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "tulis ulang file testdata/*.golden")
+
+func testReportDocument() reportDocument {
+	return reportDocument{
+		filename:  "sales-summary",
+		title:     "Sales Summary",
+		startDate: "2026-01-01",
+		endDate:   "2026-01-31",
+		sections: []reportSection{
+			{
+				title:   "Totals",
+				columns: []string{"Metric", "Value"},
+				rows: [][]interface{}{
+					{"Transactions", 42},
+					{"Revenue", 1250000},
+					{"Average basket", 29761.9},
+					{"Change vs previous", nil},
+				},
+			},
+			{
+				title:   "Best products",
+				columns: []string{"Product", "Quantity", "Revenue", "Last sold"},
+				rows: [][]interface{}{
+					{"Kopi Susu (Gelas)", 120, 1800000, time.Date(2026, 1, 31, 21, 5, 0, 0, time.UTC)},
+					{"Roti Bakar, Keju", 35, 525000, time.Date(2026, 1, 30, 8, 0, 0, 0, time.UTC)},
+				},
+			},
+		},
+	}
+}
+
+// testLongReportDocument - cukup banyak baris untuk memenuhi lebih dari satu halaman PDF
+func testLongReportDocument() reportDocument {
+	doc := reportDocument{
+		filename: "transactions",
+		title:    "Transactions",
+		period:   "Business day 2026-01-15",
+		sections: []reportSection{{
+			columns: []string{"Transaction", "Product", "Quantity", "Subtotal"},
+		}},
+	}
+	for i := 1; i <= 150; i++ {
+		doc.sections[0].rows = append(doc.sections[0].rows,
+			[]interface{}{fmt.Sprintf("TRX-%04d", i), "Produk " + fmt.Sprint(i%7), i % 5, i * 1500})
+	}
+	return doc
+}
+
+func renderReport(t *testing.T, format string, doc reportDocument) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := NewReportExporter("Toko Maju").render(&buf, format, doc); err != nil {
+		t.Fatalf("render %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (jalankan go test -update untuk membuat golden file)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s berbeda dengan golden file %s, jalankan go test -update kalau perubahannya disengaja", name, path)
+	}
+}
+
+func TestReportExporterGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		doc    reportDocument
+	}{
+		{"summary_csv", reportFormatCSV, testReportDocument()},
+		{"summary_xlsx", reportFormatXLSX, testReportDocument()},
+		{"summary_pdf", reportFormatPDF, testReportDocument()},
+		{"transactions_pdf", reportFormatPDF, testLongReportDocument()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderReport(t, tt.format, tt.doc)
+			if again := renderReport(t, tt.format, tt.doc); !bytes.Equal(got, again) {
+				t.Fatal("output tidak deterministik")
+			}
+			assertGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestReportExporterPDFRepeatsHeaderOnEveryPage(t *testing.T) {
+	out := string(renderReport(t, reportFormatPDF, testLongReportDocument()))
+
+	count := regexp.MustCompile(`/Count (\d+)`).FindStringSubmatch(out)
+	if count == nil {
+		t.Fatal("PDF tanpa /Count halaman")
+	}
+	var pages int
+	fmt.Sscan(count[1], &pages)
+	if pages < 2 {
+		t.Fatalf("pages = %d, want at least 2", pages)
+	}
+
+	streams := regexp.MustCompile(`(?s)stream\n(.*?)endstream`).FindAllStringSubmatch(out, -1)
+	if len(streams) != pages {
+		t.Fatalf("content streams = %d, want %d", len(streams), pages)
+	}
+	for i, stream := range streams {
+		if !strings.Contains(stream[1], "/F2 8 Tf 36") || !strings.Contains(stream[1], "(Transaction  Product") {
+			t.Errorf("page %d: header kolom tidak diulang", i+1)
+		}
+		if want := fmt.Sprintf("(Page %d of %d)", i+1, pages); !strings.Contains(stream[1], want) {
+			t.Errorf("page %d: footer %q tidak ada", i+1, want)
+		}
+	}
+}
+
+func TestReportExporterWriteSetsAttachment(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewReportExporter("Toko Maju").Write(rec, reportFormatCSV, testReportDocument())
+
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="sales-summary.csv"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), renderReport(t, reportFormatCSV, testReportDocument())) {
+		t.Error("body berbeda dengan hasil render")
+	}
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"kasir/models"
+	"kasir/services"
+	"net/http"
+)
+
+// Default jumlah item top-N/bottom-N di laporan
+const defaultReportLimit = 5
+
+type ReportHandler struct {
+	service  *services.ReportService
+	exporter *ReportExporter
+}
+
+func NewReportHandler(service *services.ReportService, exporter *ReportExporter) *ReportHandler {
+	return &ReportHandler{service: service, exporter: exporter}
+}
+
+// HandleSales - GET /api/report/sales?group_by=category|product|hour|day|week|month&start_date=&end_date=&limit=&format=
+// Export csv/xlsx/pdf berisi semua baris (tanpa top/bottom).
+func (h *ReportHandler) HandleSales(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = models.SalesByProduct
+	}
+
+	limit, err := queryInt(r, "limit", defaultReportLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	format, err := reportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	breakdown, err := h.service.GetSalesBreakdown(groupBy, r.URL.Query().Get("start_date"), r.URL.Query().Get("end_date"), limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if format != reportFormatJSON {
+		h.exporter.Write(w, format, breakdownDocument(breakdown))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(breakdown)
+}
+
+// HandleZReport - GET /api/report/z?date=YYYY-MM-DD&outlet_id=
+// Tanpa date, laporan untuk hari bisnis yang sedang berjalan.
+func (h *ReportHandler) HandleZReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	outletID := 0
+	if r.URL.Query().Get("outlet_id") != "" {
+		id, err := queryInt(r, "outlet_id", 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		outletID = id
+	}
+
+	report, err := h.service.GetZReport(r.URL.Query().Get("date"), outletID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// breakdownDocument - baris breakdown penjualan; pengelompokan waktu memakai label bucket, bukan id
+func breakdownDocument(breakdown *models.SalesBreakdown) reportDocument {
+	section := reportSection{title: "Sales by " + breakdown.GroupBy}
+	timeBucket := breakdown.GroupBy != models.SalesByCategory && breakdown.GroupBy != models.SalesByProduct
+	if timeBucket {
+		section.columns = []string{"Period", "Revenue", "Quantity", "Transactions"}
+	} else {
+		section.columns = []string{"ID", "Name", "Revenue", "Quantity", "Transactions"}
+	}
+
+	for _, row := range breakdown.Rows {
+		if timeBucket {
+			section.rows = append(section.rows, []interface{}{row.Name, row.Revenue, row.Quantity, row.Transactions})
+			continue
+		}
+		section.rows = append(section.rows, []interface{}{row.ID, row.Name, row.Revenue, row.Quantity, row.Transactions})
+	}
+
+	return reportDocument{
+		filename:  "sales-by-" + breakdown.GroupBy,
+		title:     "Sales by " + breakdown.GroupBy,
+		startDate: breakdown.StartDate,
+		endDate:   breakdown.EndDate,
+		sections:  []reportSection{section},
+	}
+}
+
+// transactionLineColumns - kolom daftar transaksi, urutannya sama dengan transactionLineRow
+var transactionLineColumns = []string{"Transaction ID", "Created At", "Business Date", "Outlet ID", "Customer",
+	"Product ID", "SKU", "Product", "Quantity", "Subtotal", "COGS"}
+
+func transactionLineRow(line models.TransactionLine) []interface{} {
+	var outletID interface{}
+	if line.OutletID != nil {
+		outletID = *line.OutletID
+	}
+	return []interface{}{line.TransactionID, line.CreatedAt, line.BusinessDate, outletID, line.CustomerName,
+		line.ProductID, line.SKU, line.ProductName, line.Quantity, line.Subtotal, line.COGS}
+}
+
+// HandleTransactions - GET /api/report/transactions?start_date=&end_date=&outlet_id=&format=json|csv|xlsx|pdf
+// Satu baris per item transaksi. CSV ditulis langsung dari cursor database tanpa memuat semua baris.
+func (h *ReportHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := reportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	outletID := 0
+	if r.URL.Query().Get("outlet_id") != "" {
+		if outletID, err = queryInt(r, "outlet_id", 0); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+	period, err := h.service.Period(startDate, endDate, outletID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if format == reportFormatCSV {
+		setAttachment(w, format, "transactions")
+		cw := csv.NewWriter(w)
+		cw.Write(transactionLineColumns)
+		err := h.service.EachTransactionLine(period, func(line models.TransactionLine) error {
+			return cw.Write(cellStrings(transactionLineRow(line)))
+		})
+		cw.Flush()
+		if err != nil {
+			// response sudah terkirim sebagian; putuskan koneksi supaya client tahu file tidak lengkap
+			panic(http.ErrAbortHandler)
+		}
+		return
+	}
+
+	lines := make([]models.TransactionLine, 0)
+	err = h.service.EachTransactionLine(period, func(line models.TransactionLine) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if format == reportFormatJSON {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(lines)
+		return
+	}
+
+	section := reportSection{columns: transactionLineColumns, rows: make([][]interface{}, 0, len(lines))}
+	for _, line := range lines {
+		section.rows = append(section.rows, transactionLineRow(line))
+	}
+	h.exporter.Write(w, format, reportDocument{
+		filename:  "transactions",
+		title:     "Transactions",
+		startDate: startDate,
+		endDate:   endDate,
+		sections:  []reportSection{section},
+	})
+}
+
+// HandleInventory - GET /api/report/inventory?method=average|fifo&as_of=YYYY-MM-DD&format=
+// Nilai persediaan (harga pokok dan harga jual) per produk, kategori dan lokasi.
+func (h *ReportHandler) HandleInventory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := reportFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	valuation, err := h.service.GetInventoryValuation(r.URL.Query().Get("method"), r.URL.Query().Get("as_of"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if format != reportFormatJSON {
+		h.exporter.Write(w, format, inventoryDocument(valuation))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(valuation)
+}
+
+func inventoryDocument(valuation *models.InventoryValuation) reportDocument {
+	groupColumns := []string{"ID", "Name", "Quantity", "Value", "Retail Value"}
+	groupRows := func(groups []models.InventoryValuationGroup) [][]interface{} {
+		rows := make([][]interface{}, 0, len(groups))
+		for _, g := range groups {
+			rows = append(rows, []interface{}{g.ID, g.Name, g.Quantity, g.Value, g.RetailValue})
+		}
+		return rows
+	}
+
+	products := make([][]interface{}, 0, len(valuation.Products))
+	for _, p := range valuation.Products {
+		products = append(products, []interface{}{p.ProductID, p.SKU, p.Name, p.CategoryName, p.Quantity,
+			p.UnitCost, p.Value, p.RetailPrice, p.RetailValue})
+	}
+
+	period := "As of: current stock"
+	if valuation.AsOf != "" {
+		period = "As of: end of " + valuation.AsOf
+	}
+
+	return reportDocument{
+		filename: "inventory-valuation",
+		title:    "Inventory Valuation (" + valuation.Method + ")",
+		period:   period,
+		sections: []reportSection{
+			{title: "Summary", columns: []string{"Metric", "Value"}, rows: [][]interface{}{
+				{"Total quantity", valuation.TotalQuantity},
+				{"Total value", valuation.TotalValue},
+				{"Total retail value", valuation.TotalRetailValue},
+			}},
+			{title: "By Category", columns: groupColumns, rows: groupRows(valuation.Categories)},
+			{title: "By Location", columns: groupColumns, rows: groupRows(valuation.Locations)},
+			{title: "Products", columns: []string{"ID", "SKU", "Name", "Category", "Quantity", "Unit Cost", "Value",
+				"Retail Price", "Retail Value"}, rows: products},
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir/models"
+	"kasir/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type SerialHandler struct {
+	service *services.SerialService
+}
+
+func NewSerialHandler(service *services.SerialService) *SerialHandler {
+	return &SerialHandler{service: service}
+}
+
+// HandleSerials - GET /api/serials?product_id=&status=in_stock, daftar unit untuk dipilih kasir
+func (h *SerialHandler) HandleSerials(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	productID, err := strconv.Atoi(r.URL.Query().Get("product_id"))
+	if err != nil || productID <= 0 {
+		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && status != models.SerialInStock && status != models.SerialSold {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	serials, err := h.service.GetByProduct(productID, status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(serials)
+}
+
+// HandleSerialLookup - GET /api/serials/{serial_number}, asal unit dan transaksi penjualannya (untuk klaim garansi)
+func (h *SerialHandler) HandleSerialLookup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	serialNumber := strings.TrimPrefix(r.URL.Path, "/api/serials/")
+	if serialNumber == "" {
+		http.Error(w, "Invalid serial number", http.StatusBadRequest)
+		return
+	}
+
+	serials, err := h.service.GetBySerialNumber(serialNumber)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(serials) == 0 {
+		http.Error(w, "serial number tidak ditemukan", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(serials)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"kasir/models"
+	"kasir/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type StockTransferHandler struct {
+	service *services.StockTransferService
+}
+
+func NewStockTransferHandler(service *services.StockTransferService) *StockTransferHandler {
+	return &StockTransferHandler{service: service}
+}
+
+// HandleStockTransfers - GET/POST /api/stock-transfers
+func (h *StockTransferHandler) HandleStockTransfers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetAll(w, r)
+	case http.MethodPost:
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleStockTransferByID - GET /api/stock-transfers/{id}
+// dan POST /api/stock-transfers/{id}/ship, /receive, /cancel
+func (h *StockTransferHandler) HandleStockTransferByID(w http.ResponseWriter, r *http.Request) {
+	idStr, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/stock-transfers/"), "/")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid stock transfer ID", http.StatusBadRequest)
+		return
+	}
+
+	actions := map[string]func(int) error{
+		"ship":    h.service.Ship,
+		"receive": h.service.Receive,
+		"cancel":  h.service.Cancel,
+	}
+
+	if action == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetByID(w, r, id)
+		return
+	}
+
+	run, ok := actions[action]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := run(id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	h.GetByID(w, r, id)
+}
+
+// GetAll - GET /api/stock-transfers?status=
+func (h *StockTransferHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	transfers, err := h.service.GetAll(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfers)
+}
+
+// Create - POST /api/stock-transfers
+func (h *StockTransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var transfer models.StockTransfer
+	err := json.NewDecoder(r.Body).Decode(&transfer)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Create(&transfer)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// GetByID - GET /api/stock-transfers/{id}
+func (h *StockTransferHandler) GetByID(w http.ResponseWriter, r *http.Request, id int) {
+	transfer, err := h.service.GetByID(id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
@@ -11,11 +11,12 @@ import (
 )
 
 type TransactionHandler struct {
-	service *services.TransactionService
+	service  *services.TransactionService
+	exporter *ReportExporter
 }
 
-func NewTransactionHandler(service *services.TransactionService) *TransactionHandler {
-	return &TransactionHandler{service: service}
+func NewTransactionHandler(service *services.TransactionService, exporter *ReportExporter) *TransactionHandler {
+	return &TransactionHandler{service: service, exporter: exporter}
 }
 
 // multiple item apa aja, quantity nya
@@ -48,10 +49,12 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	transaction, err := h.service.Checkout(req.Items, true) // Enable row-level locking for concurrent transactions
+	transaction, err := h.service.Checkout(req, true) // Enable row-level locking for concurrent transactions
 	if err != nil {
 		// Check if it's a business logic error (like insufficient stock) vs internal server error
-		if strings.Contains(err.Error(), "insufficient stock") || strings.Contains(err.Error(), "not found") {
+		if strings.Contains(err.Error(), "insufficient stock") || strings.Contains(err.Error(), "not found") ||
+			strings.Contains(err.Error(), "not an outlet") || strings.Contains(err.Error(), "archived") ||
+			strings.Contains(err.Error(), "serial") || strings.Contains(err.Error(), "outlet_id is required") {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -63,15 +66,107 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(transaction)
 }
 
+// Summary - GET /api/report?start_date=&end_date=&limit=&version=&compare=&format=
+// version=1 mengembalikan bentuk response lama (best_products berisi satu produk).
+// compare=previous|last_year mengembalikan perbandingan dengan periode sebelumnya atau tahun lalu.
+// format=csv|xlsx|pdf mengembalikan ringkasan sebagai file.
 func (h *TransactionHandler) Summary(w http.ResponseWriter, r *http.Request) {
 	startDate := r.URL.Query().Get("start_date")
 	endDate := r.URL.Query().Get("end_date")
 
-	summary, err := h.service.GetTransactionSummary(startDate, endDate)
+	format, err := reportFormat(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if compare := r.URL.Query().Get("compare"); compare != models.CompareNone {
+		if format != reportFormatJSON {
+			http.Error(w, "Invalid format: compare is only available as json", http.StatusBadRequest)
+			return
+		}
+		comparison, err := h.service.CompareTransactionSummary(startDate, endDate, compare)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(comparison)
 		return
 	}
+
+	limit, err := queryInt(r, "limit", defaultReportLimit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := queryInt(r, "version", models.SalesSummaryVersion)
+	if err != nil || (version != models.SalesSummaryVersion1 && version != models.SalesSummaryVersion2) {
+		http.Error(w, "Invalid version", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.service.GetTransactionSummary(startDate, endDate, limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if format != reportFormatJSON {
+		h.exporter.Write(w, format, summaryDocument(summary))
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	if version == models.SalesSummaryVersion1 {
+		json.NewEncoder(w).Encode(summary.V1())
+		return
+	}
 	json.NewEncoder(w).Encode(summary)
 }
+
+// summaryDocument - ringkasan penjualan sebagai beberapa tabel: metrik, produk terlaris, margin dan konsumsi komponen
+func summaryDocument(summary *models.SalesSummary) reportDocument {
+	marginColumns := []string{"ID", "Name", "Quantity", "Revenue", "COGS", "Gross Profit", "Margin %"}
+	marginRows := func(margins []models.MarginSummary) [][]interface{} {
+		rows := make([][]interface{}, 0, len(margins))
+		for _, m := range margins {
+			rows = append(rows, []interface{}{m.ID, m.Name, m.Quantity, m.Revenue, m.COGS, m.GrossProfit, m.MarginPercent})
+		}
+		return rows
+	}
+
+	best := make([][]interface{}, 0, len(summary.BestProducts))
+	for _, p := range summary.BestProducts {
+		best = append(best, []interface{}{p.ID, p.Name, p.Quantity, p.Revenue})
+	}
+	components := make([][]interface{}, 0, len(summary.ComponentConsumption))
+	for _, c := range summary.ComponentConsumption {
+		components = append(components, []interface{}{c.ProductID, c.Name, c.Quantity, c.COGS})
+	}
+
+	return reportDocument{
+		filename:  "sales-summary",
+		title:     "Sales Summary",
+		startDate: summary.StartDate,
+		endDate:   summary.EndDate,
+		sections: []reportSection{
+			{title: "Summary", columns: []string{"Metric", "Value"}, rows: [][]interface{}{
+				{"Total revenue", summary.TotalRevenue},
+				{"Total transactions", summary.TotalTransactions},
+				{"Total items", summary.TotalItems},
+				{"Average basket value", summary.AverageBasketValue},
+				{"Items per transaction", summary.ItemsPerTransaction},
+				{"Total COGS", summary.TotalCOGS},
+				{"Gross profit", summary.GrossProfit},
+				{"Gross margin %", summary.GrossMarginPercent},
+			}},
+			{title: "Best Products", columns: []string{"ID", "Name", "Quantity", "Revenue"}, rows: best},
+			{title: "Margin by Category", columns: marginColumns, rows: marginRows(summary.MarginByCategory)},
+			{title: "Margin by Product", columns: marginColumns, rows: marginRows(summary.MarginByProduct)},
+			{title: "Component Consumption", columns: []string{"ID", "Name", "Quantity", "COGS"}, rows: components},
+		},
+	}
+}
@@ -1,21 +1,33 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"kasir/database"
+	"kasir/events"
 	"kasir/handlers"
+	"kasir/media"
+	"kasir/models"
 	"kasir/repositories"
 	"kasir/services"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Port   string `mapstructure:"PORT"`
-	DBConn string `mapstructure:"DB_CONN"`
+	Port         string `mapstructure:"PORT"`
+	DBConn       string `mapstructure:"DB_CONN"`
+	MediaDir     string `mapstructure:"MEDIA_DIR"`
+	MediaBaseURL string `mapstructure:"MEDIA_BASE_URL"`
+	// Zona waktu toko (WIB/WITA/WIT atau nama IANA) dan jam tutup hari bisnis (HH:MM)
+	StoreTimezone     string `mapstructure:"STORE_TIMEZONE"`
+	BusinessDayCutoff string `mapstructure:"BUSINESS_DAY_CUTOFF"`
+	// Nama toko di header export laporan PDF
+	StoreName string `mapstructure:"STORE_NAME"`
 }
 
 func main() {
@@ -28,9 +40,27 @@ func main() {
 		_ = viper.ReadInConfig()
 	}
 
+	viper.SetDefault("MEDIA_DIR", "./uploads")
+	viper.SetDefault("MEDIA_BASE_URL", "/media")
+	viper.SetDefault("STORE_TIMEZONE", "WIB")
+	viper.SetDefault("BUSINESS_DAY_CUTOFF", "00:00")
+	viper.SetDefault("STORE_NAME", "Kasir")
+
 	config := Config{
-		Port:   viper.GetString("PORT"),
-		DBConn: viper.GetString("DB_CONN"),
+		Port:         viper.GetString("PORT"),
+		DBConn:       viper.GetString("DB_CONN"),
+		MediaDir:     viper.GetString("MEDIA_DIR"),
+		MediaBaseURL: viper.GetString("MEDIA_BASE_URL"),
+
+		StoreTimezone:     viper.GetString("STORE_TIMEZONE"),
+		BusinessDayCutoff: viper.GetString("BUSINESS_DAY_CUTOFF"),
+		StoreName:         viper.GetString("STORE_NAME"),
+	}
+
+	calendar, err := models.NewBusinessCalendar(config.StoreTimezone, config.BusinessDayCutoff)
+	if err != nil {
+		fmt.Printf("Invalid store calendar config: %v\n", err)
+		return
 	}
 
 	fmt.Printf("Attempting to connect to database with connection string: %s\n", config.DBConn)
@@ -42,9 +72,16 @@ func main() {
 	}
 	defer db.Close()
 
+	// Media storage (foto produk)
+	mediaStorage, err := media.NewLocalStorage(config.MediaDir, config.MediaBaseURL)
+	if err != nil {
+		fmt.Printf("Failed to initialize media storage: %v\n", err)
+		return
+	}
+
 	// Product setup
 	productRepository := repositories.NewProductRepository(db)
-	productService := services.NewProductService(productRepository)
+	productService := services.NewProductService(productRepository, mediaStorage)
 	productHandler := handlers.NewProductHandler(productService)
 
 	// Category setup
@@ -52,19 +89,86 @@ func main() {
 	categoryService := services.NewCategoryService(categoryRepository)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 
+	// Export laporan (CSV/XLSX/PDF)
+	reportExporter := handlers.NewReportExporter(config.StoreName)
+
+	// Event bus penjualan untuk dashboard live
+	dashboardBus := events.NewBus()
+
 	// Transaction setup
 	transactionRepository := repositories.NewTransactionRepository(db)
-	transactionService := services.NewTransactionService(transactionRepository)
-	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	transactionService := services.NewTransactionService(transactionRepository, calendar, dashboardBus)
+	transactionHandler := handlers.NewTransactionHandler(transactionService, reportExporter)
+	dashboardHandler := handlers.NewDashboardHandler(transactionService)
+
+	// Report setup
+	reportRepository := repositories.NewReportRepository(db, calendar)
+	reportService := services.NewReportService(reportRepository, calendar)
+	reportHandler := handlers.NewReportHandler(reportService, reportExporter)
+
+	// Supplier setup
+	supplierRepository := repositories.NewSupplierRepository(db)
+	supplierService := services.NewSupplierService(supplierRepository)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
+
+	// Purchase order setup
+	purchaseOrderRepository := repositories.NewPurchaseOrderRepository(db, productRepository)
+	purchaseOrderService := services.NewPurchaseOrderService(purchaseOrderRepository)
+	purchaseOrderHandler := handlers.NewPurchaseOrderHandler(purchaseOrderService)
+
+	// Batch setup
+	batchRepository := repositories.NewBatchRepository(db)
+	batchService := services.NewBatchService(batchRepository)
+	batchHandler := handlers.NewBatchHandler(batchService)
+
+	// Serial number setup
+	serialRepository := repositories.NewSerialRepository(db)
+	serialService := services.NewSerialService(serialRepository)
+	serialHandler := handlers.NewSerialHandler(serialService)
+
+	// Location setup
+	locationRepository := repositories.NewLocationRepository(db)
+	locationService := services.NewLocationService(locationRepository)
+	locationHandler := handlers.NewLocationHandler(locationService)
+
+	// Stock transfer setup
+	stockTransferRepository := repositories.NewStockTransferRepository(db)
+	stockTransferService := services.NewStockTransferService(stockTransferRepository)
+	stockTransferHandler := handlers.NewStockTransferHandler(stockTransferService)
+
+	// Price list setup
+	priceListRepository := repositories.NewPriceListRepository(db)
+	priceListService := services.NewPriceListService(priceListRepository)
+	priceListHandler := handlers.NewPriceListHandler(priceListService)
+
+	// Scheduled price change setup
+	priceChangeRepository := repositories.NewPriceChangeRepository(db)
+	priceChangeService := services.NewPriceChangeService(priceChangeRepository)
+	priceChangeHandler := handlers.NewPriceChangeHandler(priceChangeService)
+
+	// Background job: terapkan perubahan harga terjadwal setiap menit
+	go priceChangeService.RunScheduler(context.Background(), time.Minute)
 
 	// Register routes
 	http.HandleFunc("/health", handlers.GetHealthStatus)
 
+	// Media files
+	mediaPrefix := strings.TrimSuffix(config.MediaBaseURL, "/") + "/"
+	http.Handle(mediaPrefix, http.StripPrefix(mediaPrefix, mediaStorage.Handler()))
+
 	// Transaction routes
 	http.HandleFunc("/api/transactions/checkout", transactionHandler.HandleCheckout)
 
 	// Transaction report
 	http.HandleFunc("/api/report", transactionHandler.Summary)
+	http.HandleFunc("/api/report/reorder", productHandler.ReorderSuggestions)
+	http.HandleFunc("/api/report/sales", reportHandler.HandleSales)
+	http.HandleFunc("/api/report/z", reportHandler.HandleZReport)
+	http.HandleFunc("/api/report/transactions", reportHandler.HandleTransactions)
+	http.HandleFunc("/api/report/inventory", reportHandler.HandleInventory)
+
+	// Dashboard live (SSE)
+	http.HandleFunc("/api/dashboard/stream", dashboardHandler.HandleStream)
 
 	// Category routes
 	http.HandleFunc("/api/categories", categoryHandler.HandleCategories)
@@ -74,6 +178,38 @@ func main() {
 	http.HandleFunc("/api/produk", productHandler.HandleProducts)
 	http.HandleFunc("/api/produk/", productHandler.HandleProductByID)
 
+	// Supplier routes
+	http.HandleFunc("/api/suppliers", supplierHandler.HandleSuppliers)
+	http.HandleFunc("/api/suppliers/", supplierHandler.HandleSupplierByID)
+
+	// Purchase order routes
+	http.HandleFunc("/api/purchase-orders", purchaseOrderHandler.HandlePurchaseOrders)
+	http.HandleFunc("/api/purchase-orders/", purchaseOrderHandler.HandlePurchaseOrderByID)
+
+	// Batch routes
+	http.HandleFunc("/api/batches", batchHandler.HandleBatches)
+	http.HandleFunc("/api/batches/expiring", batchHandler.HandleExpiring)
+
+	// Serial number routes
+	http.HandleFunc("/api/serials", serialHandler.HandleSerials)
+	http.HandleFunc("/api/serials/", serialHandler.HandleSerialLookup)
+
+	// Location routes
+	http.HandleFunc("/api/locations", locationHandler.HandleLocations)
+	http.HandleFunc("/api/locations/", locationHandler.HandleLocationByID)
+
+	// Stock transfer routes
+	http.HandleFunc("/api/stock-transfers", stockTransferHandler.HandleStockTransfers)
+	http.HandleFunc("/api/stock-transfers/", stockTransferHandler.HandleStockTransferByID)
+
+	// Price list routes
+	http.HandleFunc("/api/price-lists", priceListHandler.HandlePriceLists)
+	http.HandleFunc("/api/price-lists/", priceListHandler.HandlePriceListByID)
+
+	// Scheduled price change routes
+	http.HandleFunc("/api/price-changes", priceChangeHandler.HandlePriceChanges)
+	http.HandleFunc("/api/price-changes/", priceChangeHandler.HandlePriceChangeByID)
+
 	addr := ":" + config.Port
 
 	fmt.Printf("Server running on port %s\n", config.Port)
@@ -0,0 +1,89 @@
+// Package media - penyimpanan file media (foto produk) dan pembuatan thumbnail
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage - tempat penyimpanan file media. Key berupa path relatif dengan separator "/",
+// mis. "products/12/ab34.jpg". Implementasi lain (mis. object storage) cukup memenuhi interface ini.
+type Storage interface {
+	Save(key string, r io.Reader) error
+	Delete(key string) error
+	URL(key string) string
+}
+
+// LocalStorage - simpan file di filesystem lokal, diserve lewat Handler di bawah baseURL
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStorage(dir, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid media key: %q", key)
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+// Save - tulis ke file sementara dulu lalu rename, supaya file yang sedang diserve tidak pernah setengah jadi
+func (s *LocalStorage) Save(key string, r io.Reader) error {
+	dest, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dest)
+}
+
+// Delete - hapus file; file yang sudah tidak ada tidak dianggap error
+func (s *LocalStorage) Delete(key string) error {
+	dest, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// Handler - serve file media; dipasang di main dengan http.StripPrefix(baseURL + "/")
+func (s *LocalStorage) Handler() http.Handler {
+	return http.FileServer(http.Dir(s.dir))
+}
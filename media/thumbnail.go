@@ -0,0 +1,56 @@
+package media
+
+import (
+	"image"
+	"image/color"
+)
+
+// Thumbnail - perkecil gambar supaya muat di kotak maxSize x maxSize dengan rasio tetap.
+// Pakai rata-rata area (box filter) supaya hasil tidak pecah; gambar yang sudah kecil tidak diperbesar.
+// Bagian transparan diratakan ke latar putih karena thumbnail disimpan sebagai JPEG.
+func Thumbnail(src image.Image, maxSize int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	tw, th := w, h
+	if w > maxSize || h > maxSize {
+		tw, th = maxSize, maxSize
+		if w > h {
+			th = max(1, h*maxSize/w)
+		} else {
+			tw = max(1, w*maxSize/h)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0 := b.Min.Y + y*h/th
+		y1 := max(y0+1, b.Min.Y+(y+1)*h/th)
+		for x := 0; x < tw; x++ {
+			x0 := b.Min.X + x*w/tw
+			x1 := max(x0+1, b.Min.X+(x+1)*w/tw)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			// Warna dari RGBA() sudah premultiplied, jadi latar putih cukup ditambah (1 - alpha)
+			bg := 0xffff - a/n
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r/n + bg),
+				G: uint16(g/n + bg),
+				B: uint16(bl/n + bg),
+				A: 0xffff,
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type ProductBatch struct {
+	ID               int       `json:"id"`
+	ProductID        int       `json:"product_id"`
+	ProductName      string    `json:"product_name,omitempty"`
+	BatchNumber      string    `json:"batch_number,omitempty"`
+	ExpiryDate       string    `json:"expiry_date"`
+	ReceivedQuantity int       `json:"received_quantity"`
+	Quantity         int       `json:"quantity"`
+	DaysUntilExpiry  int       `json:"days_until_expiry"`
+	Expired          bool      `json:"expired"`
+	CreatedAt        time.Time `json:"created_at"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// Field yang bisa diubah lewat bulk update
+const (
+	BulkFieldPrice = "price"
+	BulkFieldStock = "stock"
+)
+
+// Operasi bulk update; value negatif pada increase berarti penurunan
+const (
+	BulkSet             = "set"
+	BulkIncreaseAmount  = "increase_amount"
+	BulkIncreasePercent = "increase_percent"
+)
+
+// BulkUpdateFilter - produk yang terkena bulk update; minimal salah satu kriteria harus diisi,
+// kriteria yang diisi digabung dengan AND. CategoryID ikut mencakup subkategori.
+type BulkUpdateFilter struct {
+	CategoryID int   `json:"category_id,omitempty"`
+	SupplierID int   `json:"supplier_id,omitempty"`
+	IDs        []int `json:"ids,omitempty"`
+}
+
+// BulkUpdateRequest - POST /api/produk/bulk-update
+type BulkUpdateRequest struct {
+	Filter    BulkUpdateFilter `json:"filter"`
+	Field     string           `json:"field"`
+	Operation string           `json:"operation"`
+	Value     float64          `json:"value"`
+	Rounding  int              `json:"rounding,omitempty"` // bulatkan hasil ke kelipatan terdekat: 0 (tidak), 100 atau 500
+}
+
+type BulkUpdateItem struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name"`
+	OldValue  int    `json:"old_value"`
+	NewValue  int    `json:"new_value"`
+}
+
+// BulkUpdateResult - preview (dry run) atau hasil bulk update
+type BulkUpdateResult struct {
+	DryRun  bool             `json:"dry_run"`
+	Applied bool             `json:"applied"`
+	Field   string           `json:"field"`
+	Matched int              `json:"matched"`
+	Changed int              `json:"changed"`
+	Items   []BulkUpdateItem `json:"items"`
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Alias zona waktu Indonesia yang boleh dipakai di STORE_TIMEZONE
+var storeTimezoneAliases = map[string]string{
+	"WIB":  "Asia/Jakarta",
+	"WITA": "Asia/Makassar",
+	"WIT":  "Asia/Jayapura",
+}
+
+// BusinessCalendar - zona waktu toko dan jam tutup hari bisnis.
+// Dengan cutoff 04:00, penjualan jam 02:00 tanggal 2 masih masuk hari bisnis tanggal 1.
+type BusinessCalendar struct {
+	Location *time.Location
+	Cutoff   time.Duration // offset dari tengah malam, 0 <= Cutoff < 24 jam
+}
+
+// NewBusinessCalendar - timezone berupa WIB/WITA/WIT atau nama IANA, cutoff format "HH:MM" (kosong = 00:00)
+func NewBusinessCalendar(timezone, cutoff string) (*BusinessCalendar, error) {
+	name := strings.TrimSpace(timezone)
+	if alias, ok := storeTimezoneAliases[strings.ToUpper(name)]; ok {
+		name = alias
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store timezone %q: %w", timezone, err)
+	}
+
+	var offset time.Duration
+	if cutoff != "" {
+		t, err := time.Parse("15:04", cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business day cutoff %q: expected HH:MM", cutoff)
+		}
+		offset = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+
+	return &BusinessCalendar{Location: loc, Cutoff: offset}, nil
+}
+
+// DayStart - awal hari bisnis untuk tanggal (YYYY-MM-DD) di zona waktu toko. Cutoff dihitung
+// sebagai jam dinding, jadi di hari pergantian DST hari bisnis tetap mulai jam cutoff lokal.
+func (c *BusinessCalendar) DayStart(date time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, int(c.Cutoff/time.Minute), 0, 0, c.Location)
+}
+
+// BusinessDate - tanggal hari bisnis tempat sebuah waktu jatuh
+func (c *BusinessCalendar) BusinessDate(t time.Time) time.Time {
+	local := t.In(c.Location)
+	y, m, d := local.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, c.Location)
+	if local.Before(c.DayStart(date)) {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// ParseDate - parse tanggal YYYY-MM-DD; nama param dipakai di pesan error
+func (c *BusinessCalendar) ParseDate(param, value string) (time.Time, error) {
+	date, err := time.ParseInLocation("2006-01-02", value, c.Location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %q, expected YYYY-MM-DD", param, value)
+	}
+	return date, nil
+}
+
+// Period - periode laporan dari start_date sampai end_date (inklusif, dalam hari bisnis).
+// Tanggal yang kosong berarti tanpa batas di sisi tersebut.
+func (c *BusinessCalendar) Period(startDate, endDate string) (ReportPeriod, error) {
+	period := ReportPeriod{StartDate: startDate, EndDate: endDate}
+
+	var start, end time.Time
+	var err error
+	if startDate != "" {
+		if start, err = c.ParseDate("start_date", startDate); err != nil {
+			return period, err
+		}
+		period.From = c.DayStart(start)
+	}
+	if endDate != "" {
+		if end, err = c.ParseDate("end_date", endDate); err != nil {
+			return period, err
+		}
+		period.To = c.DayStart(end.AddDate(0, 0, 1))
+	}
+	if startDate != "" && endDate != "" && end.Before(start) {
+		return period, fmt.Errorf("invalid period: end_date %s is before start_date %s", endDate, startDate)
+	}
+
+	return period, nil
+}
+
+// DayPeriod - periode satu hari bisnis untuk tanggal (YYYY-MM-DD di zona waktu toko)
+func (c *BusinessCalendar) DayPeriod(date time.Time) ReportPeriod {
+	day := date.Format("2006-01-02")
+	return ReportPeriod{
+		StartDate: day,
+		EndDate:   day,
+		From:      c.DayStart(date),
+		To:        c.DayStart(date.AddDate(0, 0, 1)),
+	}
+}
+
+// ReportPeriod - rentang waktu laporan [From, To), waktu nol berarti tanpa batas.
+// OutletID > 0 membatasi laporan ke transaksi satu outlet.
+type ReportPeriod struct {
+	StartDate string    `json:"start_date,omitempty"`
+	EndDate   string    `json:"end_date,omitempty"`
+	From      time.Time `json:"-"`
+	To        time.Time `json:"-"`
+	OutletID  int       `json:"outlet_id,omitempty"`
+}
@@ -0,0 +1,168 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // test DST tidak bergantung pada tzdata sistem
+)
+
+func mustCalendar(t *testing.T, timezone, cutoff string) *BusinessCalendar {
+	t.Helper()
+	c, err := NewBusinessCalendar(timezone, cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestNewBusinessCalendar(t *testing.T) {
+	tests := []struct {
+		timezone, cutoff string
+		wantLocation     string
+		wantCutoff       time.Duration
+		wantErr          string
+	}{
+		{"WIB", "", "Asia/Jakarta", 0, ""},
+		{" wita ", "04:30", "Asia/Makassar", 4*time.Hour + 30*time.Minute, ""},
+		{"Europe/Berlin", "23:59", "Europe/Berlin", 23*time.Hour + 59*time.Minute, ""},
+		{"Mars/Olympus", "", "", 0, "invalid store timezone"},
+		{"WIB", "4am", "", 0, "invalid business day cutoff"},
+		{"WIB", "24:00", "", 0, "invalid business day cutoff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone+" "+tt.cutoff, func(t *testing.T) {
+			c, err := NewBusinessCalendar(tt.timezone, tt.cutoff)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Location.String() != tt.wantLocation || c.Cutoff != tt.wantCutoff {
+				t.Errorf("calendar = %s %v, want %s %v", c.Location, c.Cutoff, tt.wantLocation, tt.wantCutoff)
+			}
+		})
+	}
+}
+
+func TestBusinessCalendarBusinessDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		cutoff   string
+		at       string
+		want     string
+	}{
+		{"before cutoff belongs to previous day", "WIB", "04:00", "2026-01-01T19:00:00Z", "2026-01-01"}, // 02:00 WIB tgl 2
+		{"at cutoff starts the new day", "WIB", "04:00", "2026-01-01T21:00:00Z", "2026-01-02"},          // 04:00 WIB
+		{"midnight cutoff", "WIB", "", "2026-01-01T17:00:00Z", "2026-01-02"},                            // 00:00 WIB
+		{"just before midnight cutoff", "WIB", "", "2026-01-01T16:59:59Z", "2026-01-01"},
+		{"utc date differs from store date", "WIT", "", "2026-06-30T15:30:00Z", "2026-07-01"},
+		// New York mulai DST 2026-03-08 02:00 EST -> 03:00 EDT
+		{"spring forward, before cutoff", "America/New_York", "04:00", "2026-03-08T07:30:00Z", "2026-03-07"}, // 03:30 EDT
+		{"spring forward, after cutoff", "America/New_York", "04:00", "2026-03-08T08:30:00Z", "2026-03-08"},  // 04:30 EDT
+		// New York selesai DST 2026-11-01 02:00 EDT -> 01:00 EST
+		{"fall back, repeated hour", "America/New_York", "04:00", "2026-11-01T06:30:00Z", "2026-10-31"}, // 01:30 EST
+		{"fall back, before cutoff", "America/New_York", "04:00", "2026-11-01T08:30:00Z", "2026-10-31"}, // 03:30 EST
+		{"fall back, at cutoff", "America/New_York", "04:00", "2026-11-01T09:00:00Z", "2026-11-01"},     // 04:00 EST
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mustCalendar(t, tt.timezone, tt.cutoff)
+			got := c.BusinessDate(mustTime(t, tt.at))
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("BusinessDate(%s) = %s, want %s", tt.at, got.Format("2006-01-02"), tt.want)
+			}
+			if h, m, s := got.Clock(); h != 0 || m != 0 || s != 0 || got.Location() != c.Location {
+				t.Errorf("BusinessDate(%s) = %v, want local midnight", tt.at, got)
+			}
+		})
+	}
+}
+
+func TestBusinessCalendarPeriod(t *testing.T) {
+	tests := []struct {
+		name               string
+		timezone, cutoff   string
+		startDate, endDate string
+		wantFrom, wantTo   string // RFC3339, "" = tanpa batas
+		wantErr            string
+	}{
+		{"single day with cutoff", "WIB", "04:00", "2026-01-01", "2026-01-01", "2025-12-31T21:00:00Z", "2026-01-01T21:00:00Z", ""},
+		{"month, midnight cutoff", "WIB", "", "2026-02-01", "2026-02-28", "2026-01-31T17:00:00Z", "2026-02-28T17:00:00Z", ""},
+		{"open start", "WIB", "04:00", "", "2026-01-01", "", "2026-01-01T21:00:00Z", ""},
+		{"open end", "WIB", "04:00", "2026-01-01", "", "2025-12-31T21:00:00Z", "", ""},
+		{"all time", "WIB", "04:00", "", "", "", "", ""},
+		// hari bisnis 7 Maret hanya 23 jam, 31 Oktober 25 jam; batasnya tetap jam 04:00 lokal
+		{"day before spring forward", "America/New_York", "04:00", "2026-03-07", "2026-03-07", "2026-03-07T09:00:00Z", "2026-03-08T08:00:00Z", ""},
+		{"spring forward day", "America/New_York", "04:00", "2026-03-08", "2026-03-08", "2026-03-08T08:00:00Z", "2026-03-09T08:00:00Z", ""},
+		{"day before fall back", "America/New_York", "04:00", "2026-10-31", "2026-10-31", "2026-10-31T08:00:00Z", "2026-11-01T09:00:00Z", ""},
+		{"week across fall back", "Europe/Berlin", "05:00", "2026-10-19", "2026-10-25", "2026-10-19T03:00:00Z", "2026-10-26T04:00:00Z", ""},
+		{"end before start", "WIB", "", "2026-01-02", "2026-01-01", "", "", "end_date 2026-01-01 is before start_date 2026-01-02"},
+		{"invalid start", "WIB", "", "2026-13-01", "", "", "", "invalid start_date"},
+		{"invalid end", "WIB", "", "", "01/02/2026", "", "", "invalid end_date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mustCalendar(t, tt.timezone, tt.cutoff)
+			period, err := c.Period(tt.startDate, tt.endDate)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, bound := range []struct {
+				name string
+				got  time.Time
+				want string
+			}{{"From", period.From, tt.wantFrom}, {"To", period.To, tt.wantTo}} {
+				if bound.want == "" {
+					if !bound.got.IsZero() {
+						t.Errorf("%s = %v, want unbounded", bound.name, bound.got)
+					}
+					continue
+				}
+				if want := mustTime(t, bound.want); !bound.got.Equal(want) {
+					t.Errorf("%s = %v, want %v", bound.name, bound.got.UTC(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestBusinessCalendarDayPeriodMatchesBusinessDate(t *testing.T) {
+	c := mustCalendar(t, "America/New_York", "04:00")
+	for _, day := range []string{"2026-03-07", "2026-03-08", "2026-10-31", "2026-11-01"} {
+		date, err := c.ParseDate("date", day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		period := c.DayPeriod(date)
+		if got := c.BusinessDate(period.From).Format("2006-01-02"); got != day {
+			t.Errorf("%s: BusinessDate(From) = %s", day, got)
+		}
+		if got := c.BusinessDate(period.To.Add(-time.Second)).Format("2006-01-02"); got != day {
+			t.Errorf("%s: BusinessDate(To - 1s) = %s", day, got)
+		}
+		if got := c.BusinessDate(period.To).Format("2006-01-02"); got == day {
+			t.Errorf("%s: To is still inside the business day", day)
+		}
+	}
+}
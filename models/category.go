@@ -1,7 +1,75 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 type Category struct {
-	ID          int    `json:"id"`
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	ParentID    *int       `json:"parent_id,omitempty"` // nil = kategori root
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	Children    []Category `json:"children,omitempty"` // hanya diisi di GET /api/categories/tree
+}
+
+// CategoryUpdate - body PUT /api/categories/{id}. Kategori hanya dipindah kalau parent_id ada di body
+// (null = jadikan root), supaya client yang hanya mengubah nama/deskripsi tidak memindahkan subtree.
+type CategoryUpdate struct {
 	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
+	Description string `json:"description"`
+	ParentID    *int   `json:"parent_id"`
+	ParentSet   bool   `json:"-"` // parent_id dikirim di body
+}
+
+func (u *CategoryUpdate) UnmarshalJSON(data []byte) error {
+	type plain CategoryUpdate
+	if err := json.Unmarshal(data, (*plain)(u)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	_, u.ParentSet = fields["parent_id"]
+	return nil
+}
+
+// CategoryArchiveOptions - apa yang dilakukan dengan produk di kategori yang diarsipkan
+type CategoryArchiveOptions struct {
+	ReassignTo   int  // pindahkan produk ke kategori ini
+	Uncategorize bool // lepas kategori produk (category_id NULL)
+}
+
+// CategoryInUseError - kategori masih punya produk dan tidak ada opsi yang dipilih
+type CategoryInUseError struct {
+	CategoryID   int
+	ProductCount int
+}
+
+func (e *CategoryInUseError) Error() string {
+	return fmt.Sprintf("category %d still has %d products: choose reassign_to or uncategorize", e.CategoryID, e.ProductCount)
+}
+
+// CategoryMergeRequest - POST /api/categories/{id}/merge, {id} adalah kategori sumber
+type CategoryMergeRequest struct {
+	TargetID int `json:"target_id"`
+}
+
+type CategoryMergeProduct struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// CategoryMergeResult - preview (dry run) atau hasil merge kategori
+type CategoryMergeResult struct {
+	SourceID      int                    `json:"source_id"`
+	TargetID      int                    `json:"target_id"`
+	DryRun        bool                   `json:"dry_run"`
+	Applied       bool                   `json:"applied"`
+	Products      []CategoryMergeProduct `json:"products"`
+	Subcategories []int                  `json:"subcategories"` // subkategori langsung yang dipindah ke target
 }
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCategoryUpdateUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		body          string
+		wantParentID  *int
+		wantParentSet bool
+	}{
+		{`{"name": "Minuman"}`, nil, false},
+		{`{"name": "Minuman", "parent_id": null}`, nil, true},
+		{`{"name": "Minuman", "parent_id": 7}`, intPtr(7), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			var u CategoryUpdate
+			if err := json.Unmarshal([]byte(tt.body), &u); err != nil {
+				t.Fatal(err)
+			}
+			if u.Name != "Minuman" {
+				t.Errorf("Name = %q, want Minuman", u.Name)
+			}
+			if u.ParentSet != tt.wantParentSet {
+				t.Errorf("ParentSet = %v, want %v", u.ParentSet, tt.wantParentSet)
+			}
+			if (u.ParentID == nil) != (tt.wantParentID == nil) || (u.ParentID != nil && *u.ParentID != *tt.wantParentID) {
+				t.Errorf("ParentID = %v, want %v", u.ParentID, tt.wantParentID)
+			}
+		})
+	}
+
+	var u CategoryUpdate
+	if err := json.Unmarshal([]byte(`{"parent_id": "x"}`), &u); err == nil {
+		t.Error("invalid parent_id accepted")
+	}
+}
+
+func intPtr(n int) *int { return &n }
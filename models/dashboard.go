@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Jenis event dashboard
+const (
+	DashboardEventSnapshot = "snapshot" // total saat client baru terhubung
+	DashboardEventSale     = "sale"     // transaksi baru berhasil di-commit
+)
+
+// DashboardTotals - total berjalan satu hari bisnis
+type DashboardTotals struct {
+	BusinessDate       string  `json:"business_date"`
+	Revenue            int64   `json:"revenue"`
+	Transactions       int     `json:"transactions"`
+	Items              int64   `json:"items"`
+	AverageBasketValue float64 `json:"average_basket_value"`
+}
+
+// DashboardEvent - payload SSE /api/dashboard/stream
+type DashboardEvent struct {
+	Type        string          `json:"type"`
+	Transaction *Transaction    `json:"transaction,omitempty"`
+	Totals      DashboardTotals `json:"totals"`
+	Time        time.Time       `json:"time"`
+}
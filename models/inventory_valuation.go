@@ -0,0 +1,42 @@
+package models
+
+// Metode penilaian persediaan
+const (
+	ValuationAverage = "average" // HPP rata-rata perpetual (cost_price), sama dengan yang dipakai untuk COGS
+	ValuationFIFO    = "fifo"    // stok dianggap berasal dari penerimaan paling baru
+)
+
+// InventoryValuationLine - nilai persediaan satu produk
+type InventoryValuationLine struct {
+	ProductID    int     `json:"product_id"`
+	SKU          string  `json:"sku,omitempty"`
+	Name         string  `json:"name"`
+	CategoryID   int     `json:"category_id"` // 0 = tanpa kategori
+	CategoryName string  `json:"category_name"`
+	Quantity     int     `json:"quantity"`
+	UnitCost     float64 `json:"unit_cost"`
+	Value        int64   `json:"value"`
+	RetailPrice  int     `json:"retail_price"`
+	RetailValue  int64   `json:"retail_value"`
+}
+
+// InventoryValuationGroup - total nilai persediaan per kategori atau lokasi
+type InventoryValuationGroup struct {
+	ID          int    `json:"id"` // 0 = tanpa kategori / belum dialokasikan ke lokasi
+	Name        string `json:"name"`
+	Quantity    int64  `json:"quantity"`
+	Value       int64  `json:"value"`
+	RetailValue int64  `json:"retail_value"`
+}
+
+// InventoryValuation - GET /api/report/inventory
+type InventoryValuation struct {
+	Method           string                    `json:"method"`
+	AsOf             string                    `json:"as_of,omitempty"` // kosong = stok saat ini
+	TotalQuantity    int64                     `json:"total_quantity"`
+	TotalValue       int64                     `json:"total_value"`
+	TotalRetailValue int64                     `json:"total_retail_value"`
+	Products         []InventoryValuationLine  `json:"products"`
+	Categories       []InventoryValuationGroup `json:"categories"`
+	Locations        []InventoryValuationGroup `json:"locations"`
+}
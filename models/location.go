@@ -0,0 +1,21 @@
+package models
+
+// Tipe lokasi
+const (
+	LocationOutlet    = "outlet"
+	LocationWarehouse = "warehouse"
+)
+
+type Location struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Address string `json:"address,omitempty"`
+}
+
+type LocationStock struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name,omitempty"`
+	LocationID  int    `json:"location_id"`
+	Quantity    int    `json:"quantity"`
+}
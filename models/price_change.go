@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Sumber perubahan harga di riwayat harga
+const (
+	PriceSourceManual    = "manual"
+	PriceSourceScheduled = "scheduled"
+	PriceSourceImport    = "import"
+	PriceSourceBulk      = "bulk"
+)
+
+// Status perubahan harga terjadwal
+const (
+	PriceChangePending   = "pending"
+	PriceChangeApplied   = "applied"
+	PriceChangeCancelled = "cancelled"
+)
+
+type PriceHistory struct {
+	ID          int       `json:"id"`
+	ProductID   int       `json:"product_id"`
+	OldPrice    *int      `json:"old_price"`
+	NewPrice    int       `json:"new_price"`
+	Source      string    `json:"source"`
+	ReferenceID *int      `json:"reference_id,omitempty"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+type ScheduledPriceChange struct {
+	ID          int        `json:"id"`
+	ProductID   int        `json:"product_id"`
+	ProductName string     `json:"product_name,omitempty"`
+	NewPrice    int        `json:"new_price"`
+	EffectiveAt time.Time  `json:"effective_at"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// PriceList - daftar harga yang menimpa products.price untuk outlet dan/atau tier pelanggan tertentu.
+// OutletID dan CustomerTier kosong berarti berlaku untuk semua. Active yang tidak dikirim berarti
+// aktif saat create dan tidak berubah saat update.
+type PriceList struct {
+	ID           int             `json:"id"`
+	Name         string          `json:"name"`
+	OutletID     *int            `json:"outlet_id,omitempty"`
+	CustomerTier string          `json:"customer_tier,omitempty"`
+	Active       *bool           `json:"active"`
+	Items        []PriceListItem `json:"items,omitempty"`
+}
+
+type PriceListItem struct {
+	ID          int    `json:"id"`
+	PriceListID int    `json:"price_list_id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name,omitempty"`
+	MinQuantity int    `json:"min_quantity"`
+	Price       int    `json:"price"`
+}
+
+// PriceContext - konteks untuk menentukan harga jual: outlet, tier pelanggan dan jumlah beli
+type PriceContext struct {
+	OutletID     int
+	CustomerTier string
+	Quantity     int
+}
@@ -1,9 +1,44 @@
 package models
 
+import "time"
+
 type Product struct {
-	ID       int       `json:"id"`
-	Name     string    `json:"name"`
-	Price    int       `json:"price"`
-	Stock    int       `json:"stock"`
-	Category *Category `json:"category,omitempty"`
+	ID           int                `json:"id"`
+	SKU          string             `json:"sku,omitempty"`
+	Barcode      string             `json:"barcode,omitempty"`
+	Name         string             `json:"name"`
+	Price        int                `json:"price"`
+	BasePrice    int                `json:"base_price,omitempty"`    // products.price kalau harga ditimpa price list
+	PriceListID  *int               `json:"price_list_id,omitempty"` // price list yang dipakai untuk Price
+	CostPrice    int                `json:"cost_price"`
+	Stock        int                `json:"stock"`
+	TrackSerial  bool               `json:"track_serial"`
+	TrackExpiry  bool               `json:"track_expiry"`
+	MinStock     int                `json:"min_stock"`
+	ReorderQty   int                `json:"reorder_qty"`
+	SupplierID   *int               `json:"supplier_id,omitempty"`
+	Category     *Category          `json:"category,omitempty"`
+	Components   []ProductComponent `json:"components,omitempty"`
+	ImageKey     string             `json:"-"`
+	ImageURL     string             `json:"image_url,omitempty"`
+	ThumbnailURL string             `json:"thumbnail_url,omitempty"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	ArchivedAt   *time.Time         `json:"archived_at,omitempty"`
+}
+
+// ProductUpdate - body PUT /api/produk/{id}. Field pointer yang tidak dikirim tidak mengubah nilai
+// tersimpan, supaya client lama yang belum mengenal field tersebut tidak menimpanya dengan nilai nol.
+// cost_price tidak bisa diubah lewat PUT, HPP rata-rata hanya diperbarui oleh penerimaan barang.
+type ProductUpdate struct {
+	Name        string    `json:"name"`
+	Price       int       `json:"price"`
+	Stock       *int      `json:"stock,omitempty"`
+	Category    *Category `json:"category,omitempty"`
+	SKU         *string   `json:"sku,omitempty"`     // "" = hapus SKU
+	Barcode     *string   `json:"barcode,omitempty"` // "" = hapus barcode
+	TrackSerial *bool     `json:"track_serial,omitempty"`
+	TrackExpiry *bool     `json:"track_expiry,omitempty"`
+	MinStock    *int      `json:"min_stock,omitempty"`
+	ReorderQty  *int      `json:"reorder_qty,omitempty"`
+	SupplierID  *int      `json:"supplier_id,omitempty"` // 0 = hapus supplier
 }
@@ -0,0 +1,16 @@
+package models
+
+// ProductComponent - satu baris resep produk komposit: component dipakai sebanyak Quantity per unit produk
+type ProductComponent struct {
+	ComponentID   int    `json:"component_id"`
+	ComponentName string `json:"component_name,omitempty"`
+	Quantity      int    `json:"quantity"`
+}
+
+// ComponentConsumption - jumlah komponen yang terpakai lewat penjualan produk komposit
+type ComponentConsumption struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	COGS      int64  `json:"cogs"`
+}
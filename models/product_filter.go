@@ -0,0 +1,39 @@
+package models
+
+// Status stok untuk filter listing produk
+const (
+	StockStatusInStock    = "in_stock"     // stok > 0
+	StockStatusLowStock   = "low_stock"    // 0 < stok <= min_stock
+	StockStatusOutOfStock = "out_of_stock" // stok <= 0
+)
+
+// Filter status arsip untuk listing produk
+const (
+	ArchivedExclude = "exclude"
+	ArchivedInclude = "include"
+	ArchivedOnly    = "only"
+)
+
+// ProductFilter - filter, sorting dan pagination untuk GET /api/produk
+type ProductFilter struct {
+	Name        string
+	CategoryID  int
+	MinPrice    *int
+	MaxPrice    *int
+	StockStatus string
+	Archived    string
+	OutletID    int
+	Sort        string // name | price | stock | updated_at
+	Order       string // asc | desc
+	Page        int
+	PageSize    int
+}
+
+// ProductPage - response envelope listing produk
+type ProductPage struct {
+	Data       []Product `json:"data"`
+	Page       int       `json:"page"`
+	PageSize   int       `json:"page_size"`
+	Total      int       `json:"total"`
+	TotalPages int       `json:"total_pages"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// ProductImportColumns - header file import/export katalog produk, urutan kolom export
+var ProductImportColumns = []string{"sku", "name", "category", "price", "cost_price", "stock", "min_stock", "reorder_qty", "track_expiry"}
+
+// ProductImportRow - satu baris file import yang sudah di-parse.
+// Field angka nil berarti sel kosong: dipertahankan untuk produk yang sudah ada, 0 untuk produk baru.
+type ProductImportRow struct {
+	Row         int
+	SKU         string
+	Name        string
+	Category    string
+	Price       *int
+	CostPrice   *int
+	Stock       *int
+	MinStock    *int
+	ReorderQty  *int
+	TrackExpiry *bool
+}
+
+// ProductImportError - error validasi per baris (Row 0 = error di level file, mis. header)
+type ProductImportError struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ProductImportReport - hasil import; kalau ada error atau dry run, tidak ada perubahan yang disimpan
+type ProductImportReport struct {
+	DryRun        bool                 `json:"dry_run"`
+	Applied       bool                 `json:"applied"`
+	TotalRows     int                  `json:"total_rows"`
+	Created       int                  `json:"created"`
+	Updated       int                  `json:"updated"`
+	NewCategories []string             `json:"new_categories"`
+	Errors        []ProductImportError `json:"errors"`
+}
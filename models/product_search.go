@@ -0,0 +1,8 @@
+package models
+
+// ProductSearchHit - hasil pencarian produk beserta skor relevansi dan popularitas
+type ProductSearchHit struct {
+	Product
+	Score        float64 `json:"score"`
+	SoldQuantity int     `json:"sold_quantity"` // jumlah terjual 30 hari terakhir
+}
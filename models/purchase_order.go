@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Status purchase order
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+type PurchaseOrder struct {
+	ID         int                 `json:"id"`
+	SupplierID int                 `json:"supplier_id"`
+	Supplier   *Supplier           `json:"supplier,omitempty"`
+	LocationID *int                `json:"location_id,omitempty"` // lokasi tujuan penerimaan barang
+	Status     string              `json:"status"`
+	Notes      string              `json:"notes,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	Lines      []PurchaseOrderLine `json:"lines,omitempty"`
+}
+
+type PurchaseOrderLine struct {
+	ID               int    `json:"id"`
+	PurchaseOrderID  int    `json:"purchase_order_id"`
+	ProductID        int    `json:"product_id"`
+	ProductName      string `json:"product_name,omitempty"`
+	Quantity         int    `json:"quantity"`
+	ReceivedQuantity int    `json:"received_quantity"`
+	UnitCost         int    `json:"unit_cost"`
+}
+
+type GoodsReceipt struct {
+	ID              int                `json:"id"`
+	PurchaseOrderID int                `json:"purchase_order_id"`
+	ReceivedAt      time.Time          `json:"received_at"`
+	Notes           string             `json:"notes,omitempty"`
+	Lines           []GoodsReceiptLine `json:"lines"`
+}
+
+type GoodsReceiptLine struct {
+	ID            int      `json:"id"`
+	LineID        int      `json:"line_id"`
+	ProductID     int      `json:"product_id"`
+	Quantity      int      `json:"quantity"`
+	UnitCost      int      `json:"unit_cost"`
+	BatchID       int      `json:"batch_id,omitempty"`
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+}
+
+type ReceiveItem struct {
+	LineID        int      `json:"line_id"`
+	Quantity      int      `json:"quantity"`
+	BatchNumber   string   `json:"batch_number,omitempty"`
+	ExpiryDate    string   `json:"expiry_date,omitempty"`    // YYYY-MM-DD, wajib untuk produk track_expiry
+	SerialNumbers []string `json:"serial_numbers,omitempty"` // wajib untuk produk track_serial, satu per unit
+}
+
+type ReceiveRequest struct {
+	Notes string        `json:"notes,omitempty"`
+	Items []ReceiveItem `json:"items"`
+}
+
+// PurchaseHistory - satu baris penerimaan barang untuk sebuah produk
+type PurchaseHistory struct {
+	GoodsReceiptID  int       `json:"goods_receipt_id"`
+	PurchaseOrderID int       `json:"purchase_order_id"`
+	SupplierID      int       `json:"supplier_id"`
+	SupplierName    string    `json:"supplier_name"`
+	Quantity        int       `json:"quantity"`
+	UnitCost        int       `json:"unit_cost"`
+	ReceivedAt      time.Time `json:"received_at"`
+}
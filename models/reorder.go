@@ -0,0 +1,23 @@
+package models
+
+// ReorderSuggestion - saran pemesanan ulang satu produk berdasarkan kecepatan penjualan
+type ReorderSuggestion struct {
+	ProductID         int      `json:"product_id"`
+	Name              string   `json:"name"`
+	Stock             int      `json:"stock"`
+	MinStock          int      `json:"min_stock"`
+	ReorderQty        int      `json:"reorder_qty"`
+	SoldQuantity      int      `json:"sold_quantity"`
+	AverageDailySales float64  `json:"average_daily_sales"`
+	DaysOfCover       *float64 `json:"days_of_cover"` // null kalau tidak ada penjualan
+	SuggestedQuantity int      `json:"suggested_quantity"`
+	EstimatedCost     int64    `json:"estimated_cost"`
+}
+
+// ReorderSupplierGroup - saran pemesanan ulang dikelompokkan per supplier (SupplierID 0 = belum ada supplier)
+type ReorderSupplierGroup struct {
+	SupplierID         int                 `json:"supplier_id"`
+	SupplierName       string              `json:"supplier_name"`
+	Items              []ReorderSuggestion `json:"items"`
+	TotalEstimatedCost int64               `json:"total_estimated_cost"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import "math"
+
+// MarginSummary - penjualan, HPP (COGS) dan laba kotor untuk satu produk atau kategori
+type MarginSummary struct {
+	ID            int     `json:"id"`
+	Name          string  `json:"name"`
+	Quantity      int     `json:"quantity"`
+	Revenue       int64   `json:"revenue"`
+	COGS          int64   `json:"cogs"`
+	GrossProfit   int64   `json:"gross_profit"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+// MarginPercent - laba kotor sebagai persentase dari pendapatan, dibulatkan 2 desimal
+func MarginPercent(revenue, grossProfit int64) float64 {
+	if revenue == 0 {
+		return 0
+	}
+	return math.Round(float64(grossProfit)/float64(revenue)*10000) / 100
+}
+
+// Versi JSON SalesSummary; versi 1 adalah bentuk lama (map dengan best_products berisi satu produk)
+const (
+	SalesSummaryVersion1 = 1
+	SalesSummaryVersion2 = 2
+	SalesSummaryVersion  = SalesSummaryVersion2
+)
+
+// BestProduct - produk terlaris berdasarkan quantity
+type BestProduct struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Revenue  int64  `json:"revenue"`
+}
+
+// SalesSummary - GET /api/report
+type SalesSummary struct {
+	Version              int                    `json:"version"`
+	StartDate            string                 `json:"start_date,omitempty"`
+	EndDate              string                 `json:"end_date,omitempty"`
+	TotalRevenue         int64                  `json:"total_revenue"`
+	TotalTransactions    int                    `json:"total_transactions"`
+	TotalItems           int64                  `json:"total_items"`
+	AverageBasketValue   float64                `json:"average_basket_value"`
+	ItemsPerTransaction  float64                `json:"items_per_transaction"`
+	TotalCOGS            int64                  `json:"total_cogs"`
+	GrossProfit          int64                  `json:"gross_profit"`
+	GrossMarginPercent   float64                `json:"gross_margin_percent"`
+	BestProducts         []BestProduct          `json:"best_products"`
+	MarginByProduct      []MarginSummary        `json:"margin_by_product"`
+	MarginByCategory     []MarginSummary        `json:"margin_by_category"`
+	ComponentConsumption []ComponentConsumption `json:"component_consumption"`
+}
+
+// SalesSummaryV1 - bentuk response lama /api/report, untuk client yang memakai ?version=1
+type SalesSummaryV1 struct {
+	TotalRevenue         int64                  `json:"total_revenue"`
+	TotalTransaction     int                    `json:"total_transaction"`
+	BestProducts         BestProductV1          `json:"best_products"`
+	TotalCOGS            int64                  `json:"total_cogs"`
+	GrossProfit          int64                  `json:"gross_profit"`
+	GrossMarginPercent   float64                `json:"gross_margin_percent"`
+	MarginByProduct      []MarginSummary        `json:"margin_by_product"`
+	MarginByCategory     []MarginSummary        `json:"margin_by_category"`
+	ComponentConsumption []ComponentConsumption `json:"component_consumption"`
+}
+
+type BestProductV1 struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+// V1 - konversi ke bentuk response lama; best_products hanya berisi produk terlaris pertama
+func (s *SalesSummary) V1() SalesSummaryV1 {
+	v1 := SalesSummaryV1{
+		TotalRevenue:         s.TotalRevenue,
+		TotalTransaction:     s.TotalTransactions,
+		TotalCOGS:            s.TotalCOGS,
+		GrossProfit:          s.GrossProfit,
+		GrossMarginPercent:   s.GrossMarginPercent,
+		MarginByProduct:      s.MarginByProduct,
+		MarginByCategory:     s.MarginByCategory,
+		ComponentConsumption: s.ComponentConsumption,
+	}
+	if len(s.BestProducts) > 0 {
+		v1.BestProducts = BestProductV1{Name: s.BestProducts[0].Name, Quantity: s.BestProducts[0].Quantity}
+	}
+	return v1
+}
+
+// Ratio - a/b dibulatkan 2 desimal, 0 kalau b nol
+func Ratio(a, b int64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return math.Round(float64(a)/float64(b)*100) / 100
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// Mode perbandingan periode laporan
+const (
+	CompareNone     = ""
+	ComparePrevious = "previous"  // periode dengan panjang sama tepat sebelum periode sekarang
+	CompareLastYear = "last_year" // tanggal yang sama tahun lalu
+)
+
+// ComparisonPeriod - periode pembanding untuk mode compare; start_date dan end_date wajib diisi
+func (c *BusinessCalendar) ComparisonPeriod(period ReportPeriod, mode string) (ReportPeriod, error) {
+	if period.StartDate == "" || period.EndDate == "" {
+		return ReportPeriod{}, fmt.Errorf("start_date and end_date are required for compare=%s", mode)
+	}
+	start, err := c.ParseDate("start_date", period.StartDate)
+	if err != nil {
+		return ReportPeriod{}, err
+	}
+	end, err := c.ParseDate("end_date", period.EndDate)
+	if err != nil {
+		return ReportPeriod{}, err
+	}
+
+	switch mode {
+	case ComparePrevious:
+		days := int(end.Sub(start).Hours()/24+0.5) + 1
+		end = start.AddDate(0, 0, -1)
+		start = start.AddDate(0, 0, -days)
+	case CompareLastYear:
+		start = start.AddDate(-1, 0, 0)
+		end = end.AddDate(-1, 0, 0)
+	default:
+		return ReportPeriod{}, fmt.Errorf("invalid compare: %q (allowed: previous, last_year)", mode)
+	}
+
+	return c.Period(start.Format("2006-01-02"), end.Format("2006-01-02"))
+}
+
+// Delta - nilai satu metrik di periode sekarang vs pembanding.
+// ChangePercent nil kalau nilai pembanding nol (persentase tidak terdefinisi).
+type Delta struct {
+	Current       float64  `json:"current"`
+	Previous      float64  `json:"previous"`
+	Change        float64  `json:"change"`
+	ChangePercent *float64 `json:"change_percent"`
+}
+
+// NewDelta - selisih absolut dan persentase, dibulatkan 2 desimal
+func NewDelta(current, previous float64) Delta {
+	d := Delta{
+		Current:  current,
+		Previous: previous,
+		Change:   math.Round((current-previous)*100) / 100,
+	}
+	if previous != 0 {
+		pct := math.Round((current-previous)/math.Abs(previous)*10000) / 100
+		d.ChangePercent = &pct
+	}
+	return d
+}
+
+// SalesSummaryDeltas - perbandingan metrik utama SalesSummary
+type SalesSummaryDeltas struct {
+	TotalRevenue        Delta `json:"total_revenue"`
+	TotalTransactions   Delta `json:"total_transactions"`
+	TotalItems          Delta `json:"total_items"`
+	AverageBasketValue  Delta `json:"average_basket_value"`
+	ItemsPerTransaction Delta `json:"items_per_transaction"`
+	TotalCOGS           Delta `json:"total_cogs"`
+	GrossProfit         Delta `json:"gross_profit"`
+	GrossMarginPercent  Delta `json:"gross_margin_percent"`
+}
+
+// ComparisonRow - perbandingan satu produk atau kategori
+type ComparisonRow struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Quantity    Delta  `json:"quantity"`
+	Revenue     Delta  `json:"revenue"`
+	GrossProfit Delta  `json:"gross_profit"`
+}
+
+// ReportPeriodRange - tanggal periode di response perbandingan
+type ReportPeriodRange struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// SalesComparison - GET /api/report?compare=previous|last_year
+type SalesComparison struct {
+	Compare    string             `json:"compare"`
+	Current    ReportPeriodRange  `json:"current"`
+	Previous   ReportPeriodRange  `json:"previous"`
+	Metrics    SalesSummaryDeltas `json:"metrics"`
+	ByCategory []ComparisonRow    `json:"by_category"`
+	ByProduct  []ComparisonRow    `json:"by_product"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewDelta(t *testing.T) {
+	tests := []struct {
+		name              string
+		current, previous float64
+		wantChange        float64
+		wantPercent       *float64
+	}{
+		{"growth", 150, 100, 50, ptr(50.0)},
+		{"decline", 75, 100, -25, ptr(-25.0)},
+		{"unchanged", 100, 100, 0, ptr(0.0)},
+		{"rounded to 2 decimals", 2, 3, -1, ptr(-33.33)},
+		{"negative previous uses its magnitude", 50, -100, 150, ptr(150.0)},
+		{"zero previous has no percentage", 250, 0, 250, nil},
+		{"both zero", 0, 0, 0, nil},
+		{"fractional values", 12.5, 10, 2.5, ptr(25.0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDelta(tt.current, tt.previous)
+			if d.Current != tt.current || d.Previous != tt.previous || d.Change != tt.wantChange {
+				t.Errorf("NewDelta(%v, %v) = %+v, want change %v", tt.current, tt.previous, d, tt.wantChange)
+			}
+			switch {
+			case tt.wantPercent == nil && d.ChangePercent != nil:
+				t.Errorf("ChangePercent = %v, want nil", *d.ChangePercent)
+			case tt.wantPercent != nil && d.ChangePercent == nil:
+				t.Errorf("ChangePercent = nil, want %v", *tt.wantPercent)
+			case tt.wantPercent != nil && *d.ChangePercent != *tt.wantPercent:
+				t.Errorf("ChangePercent = %v, want %v", *d.ChangePercent, *tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestComparisonPeriod(t *testing.T) {
+	c := mustCalendar(t, "WIB", "04:00")
+	tests := []struct {
+		name               string
+		startDate, endDate string
+		mode               string
+		wantStart, wantEnd string
+		wantErr            string
+	}{
+		{"previous single day", "2026-03-01", "2026-03-01", ComparePrevious, "2026-02-28", "2026-02-28", ""},
+		{"previous week", "2026-03-09", "2026-03-15", ComparePrevious, "2026-03-02", "2026-03-08", ""},
+		{"previous month length", "2026-03-01", "2026-03-31", ComparePrevious, "2026-01-29", "2026-02-28", ""},
+		{"last year", "2026-03-01", "2026-03-31", CompareLastYear, "2025-03-01", "2025-03-31", ""},
+		{"last year from leap day", "2028-02-29", "2028-02-29", CompareLastYear, "2027-03-01", "2027-03-01", ""},
+		{"open period", "2026-03-01", "", ComparePrevious, "", "", "start_date and end_date are required"},
+		{"unknown mode", "2026-03-01", "2026-03-02", "week", "", "", "invalid compare"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			period := ReportPeriod{StartDate: tt.startDate, EndDate: tt.endDate}
+			got, err := c.ComparisonPeriod(period, tt.mode)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.StartDate != tt.wantStart || got.EndDate != tt.wantEnd {
+				t.Errorf("ComparisonPeriod = %s..%s, want %s..%s", got.StartDate, got.EndDate, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func ptr(f float64) *float64 { return &f }
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Pengelompokan laporan penjualan
+const (
+	SalesByCategory = "category"
+	SalesByProduct  = "product"
+	SalesByHour     = "hour"
+	SalesByDay      = "day"
+	SalesByWeek     = "week"
+	SalesByMonth    = "month"
+)
+
+// SalesBreakdownRow - penjualan satu kelompok: produk, kategori atau bucket waktu
+type SalesBreakdownRow struct {
+	ID           int        `json:"id,omitempty"`           // id produk/kategori (0 = tanpa kategori)
+	Name         string     `json:"name"`                   // nama produk/kategori atau label bucket
+	BucketStart  *time.Time `json:"bucket_start,omitempty"` // awal bucket untuk pengelompokan waktu
+	Revenue      int64      `json:"revenue"`
+	Quantity     int64      `json:"quantity"`
+	Transactions int        `json:"transactions"`
+}
+
+// SalesBreakdown - GET /api/report/sales
+type SalesBreakdown struct {
+	GroupBy   string              `json:"group_by"`
+	StartDate string              `json:"start_date,omitempty"`
+	EndDate   string              `json:"end_date,omitempty"`
+	Rows      []SalesBreakdownRow `json:"rows"`
+	Top       []SalesBreakdownRow `json:"top"`    // N kelompok dengan revenue tertinggi
+	Bottom    []SalesBreakdownRow `json:"bottom"` // N kelompok dengan revenue terendah, mulai dari yang terendah
+}
+
+// ZReport - laporan tutup hari (Z-report) untuk satu hari bisnis, dihitung dari transaksi
+type ZReport struct {
+	BusinessDate       string              `json:"business_date"`
+	OpenedAt           time.Time           `json:"opened_at"` // awal hari bisnis (cutoff) di zona waktu toko
+	ClosedAt           time.Time           `json:"closed_at"` // akhir hari bisnis (eksklusif)
+	OutletID           int                 `json:"outlet_id,omitempty"`
+	TotalTransactions  int                 `json:"total_transactions"`
+	FirstTransactionAt *time.Time          `json:"first_transaction_at,omitempty"`
+	LastTransactionAt  *time.Time          `json:"last_transaction_at,omitempty"`
+	GrossSales         int64               `json:"gross_sales"`
+	TotalItems         int64               `json:"total_items"`
+	TotalCOGS          int64               `json:"total_cogs"`
+	GrossProfit        int64               `json:"gross_profit"`
+	AverageBasketValue float64             `json:"average_basket_value"`
+	ByCategory         []SalesBreakdownRow `json:"by_category"`
+	ByHour             []SalesBreakdownRow `json:"by_hour"`
+}
+
+// TransactionLine - satu baris item transaksi untuk daftar/export transaksi
+type TransactionLine struct {
+	TransactionID int       `json:"transaction_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	BusinessDate  string    `json:"business_date"`
+	OutletID      *int      `json:"outlet_id,omitempty"`
+	CustomerName  string    `json:"customer_name,omitempty"`
+	ProductID     int       `json:"product_id"`
+	SKU           string    `json:"sku,omitempty"`
+	ProductName   string    `json:"product_name"`
+	Quantity      int       `json:"quantity"`
+	Subtotal      int       `json:"subtotal"`
+	COGS          int64     `json:"cogs"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Status nomor serial
+const (
+	SerialInStock = "in_stock"
+	SerialSold    = "sold"
+)
+
+// ProductSerial - satu unit produk serial (IMEI/serial number) beserta asal dan penjualannya
+type ProductSerial struct {
+	ID              int        `json:"id"`
+	ProductID       int        `json:"product_id"`
+	ProductName     string     `json:"product_name"`
+	SerialNumber    string     `json:"serial_number"`
+	Status          string     `json:"status"`
+	PurchaseOrderID *int       `json:"purchase_order_id,omitempty"`
+	ReceivedAt      time.Time  `json:"received_at"`
+	TransactionID   *int       `json:"transaction_id,omitempty"`
+	SoldAt          *time.Time `json:"sold_at,omitempty"`
+	CustomerName    string     `json:"customer_name,omitempty"`
+	CustomerPhone   string     `json:"customer_phone,omitempty"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Status transfer stok antar lokasi
+const (
+	TransferDraft     = "draft"
+	TransferInTransit = "in_transit"
+	TransferReceived  = "received"
+	TransferCancelled = "cancelled"
+)
+
+type StockTransfer struct {
+	ID             int                 `json:"id"`
+	FromLocationID int                 `json:"from_location_id"`
+	ToLocationID   int                 `json:"to_location_id"`
+	Status         string              `json:"status"`
+	Notes          string              `json:"notes,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	ShippedAt      *time.Time          `json:"shipped_at,omitempty"`
+	ReceivedAt     *time.Time          `json:"received_at,omitempty"`
+	Lines          []StockTransferLine `json:"lines,omitempty"`
+}
+
+type StockTransferLine struct {
+	ID          int    `json:"id"`
+	TransferID  int    `json:"transfer_id"`
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name,omitempty"`
+	Quantity    int    `json:"quantity"`
+}
@@ -3,26 +3,36 @@ package models
 import "time"
 
 type Transaction struct {
-	ID          int                 `json:"id"`
-	TotalAmount int                 `json:"total_amount"`
-	CreatedAt   time.Time           `json:"created_at"`
-	Details     []TransactionDetail `json:"details"`
+	ID            int                 `json:"id"`
+	TotalAmount   int                 `json:"total_amount"`
+	OutletID      *int                `json:"outlet_id,omitempty"`
+	CustomerName  string              `json:"customer_name,omitempty"`
+	CustomerPhone string              `json:"customer_phone,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	Details       []TransactionDetail `json:"details"`
 }
 
 type TransactionDetail struct {
-	ID            int    `json:"id"`
-	TransactionID int    `json:"transaction_id"`
-	ProductID     int    `json:"product_id"`
-	ProductName   string `json:"product_name,omitempty"`
-	Quantity      int    `json:"quantity"`
-	Subtotal      int    `json:"subtotal"`
+	ID            int      `json:"id"`
+	TransactionID int      `json:"transaction_id"`
+	ProductID     int      `json:"product_id"`
+	ProductName   string   `json:"product_name,omitempty"`
+	Quantity      int      `json:"quantity"`
+	Subtotal      int      `json:"subtotal"`
+	CostPrice     int      `json:"cost_price"`
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
 }
 
 type CheckoutItem struct {
-	ProductID int `json:"product_id"`
-	Quantity  int `json:"quantity"`
+	ProductID     int      `json:"product_id"`
+	Quantity      int      `json:"quantity"`
+	SerialNumbers []string `json:"serial_numbers,omitempty"` // wajib untuk produk track_serial, satu per unit
 }
 
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items"`
+	OutletID      int            `json:"outlet_id,omitempty"` // outlet terminal kasir; kosong = stok total
+	CustomerTier  string         `json:"customer_tier,omitempty"`
+	CustomerName  string         `json:"customer_name,omitempty"`
+	CustomerPhone string         `json:"customer_phone,omitempty"`
+	Items         []CheckoutItem `json:"items"`
 }
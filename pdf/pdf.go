@@ -0,0 +1,238 @@
+// Package pdf - penulis PDF minimal untuk laporan tabel (A4, font Courier bawaan PDF).
+// Tidak memakai timestamp atau ID acak, jadi isi yang sama menghasilkan file yang sama persis.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	pageWidth  = 595 // A4 dalam point
+	pageHeight = 842
+	margin     = 36
+
+	fontSize   = 8
+	charWidth  = fontSize * 0.6 // lebar glyph Courier = 600/1000 em
+	lineHeight = 11
+	columnGap  = 2 // spasi antar kolom, dalam karakter
+
+	maxChars = (pageWidth - 2*margin) * 10 / (fontSize * 6) // karakter per baris tabel
+)
+
+// Table - satu bagian laporan: judul, header kolom dan baris
+type Table struct {
+	Title   string
+	Columns []string
+	Rows    [][]string
+}
+
+// Document - header (nama toko, judul, periode) diikuti tabel-tabel
+type Document struct {
+	Header []string
+	Tables []Table
+}
+
+type line struct {
+	text string
+	bold bool
+	size float64
+}
+
+// Write - render dokumen ke PDF. Header kolom tabel diulang di setiap halaman baru.
+func (d *Document) Write(w io.Writer) error {
+	pages := d.layout()
+
+	var buf bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// 1: catalog, 2: pages, 3-4: font, lalu pasangan page + content per halaman
+	const firstPage = 5
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		var content bytes.Buffer
+		y := float64(pageHeight - margin)
+		for _, l := range lines {
+			y -= l.size + 3
+			if l.text == "" {
+				continue
+			}
+			font := "F1"
+			if l.bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "BT /%s %s Tf %d %s Td (%s) Tj ET\n", font, number(l.size), margin, number(y), escape(l.text))
+		}
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(pages))
+		fmt.Fprintf(&content, "BT /F1 %d Tf %s %d Td (%s) Tj ET\n", fontSize,
+			number(pageWidth-margin-float64(len(footer))*charWidth), margin/2, escape(footer))
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// layout - pecah header dan tabel menjadi baris per halaman
+func (d *Document) layout() [][]line {
+	var pages [][]line
+	var current []line
+	used := 0.0
+	available := float64(pageHeight-2*margin) - lineHeight // sisakan tempat untuk footer
+
+	add := func(l line, repeat *line) {
+		if used+l.size+3 > available && len(current) > 0 {
+			pages = append(pages, current)
+			current, used = nil, 0
+			if repeat != nil {
+				current = append(current, *repeat)
+				used += repeat.size + 3
+			}
+		}
+		current = append(current, l)
+		used += l.size + 3
+	}
+
+	for i, text := range d.Header {
+		if i == 0 {
+			add(line{text: text, bold: true, size: 12}, nil)
+			continue
+		}
+		add(line{text: text, size: 9}, nil)
+	}
+
+	for _, t := range d.Tables {
+		add(line{size: fontSize}, nil)
+		if t.Title != "" {
+			add(line{text: t.Title, bold: true, size: 9}, nil)
+		}
+
+		widths := columnWidths(t)
+		header := line{text: formatRow(t.Columns, widths, false), bold: true, size: fontSize}
+		add(header, nil)
+		add(line{text: strings.Repeat("-", min(rowWidth(widths), maxChars)), size: fontSize}, &header)
+		for _, row := range t.Rows {
+			add(line{text: formatRow(row, widths, true), size: fontSize}, &header)
+		}
+	}
+
+	if len(current) > 0 || len(pages) == 0 {
+		pages = append(pages, current)
+	}
+	return pages
+}
+
+// columnWidths - lebar kolom (karakter) = isi terpanjang, kolom terlebar dipersempit sampai muat satu baris
+func columnWidths(t Table) []int {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = len([]rune(c))
+	}
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], len([]rune(cell)))
+			}
+		}
+	}
+
+	for rowWidth(widths) > maxChars {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 4 {
+			break
+		}
+		widths[widest]--
+	}
+	return widths
+}
+
+func rowWidth(widths []int) int {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	return total + columnGap*max(len(widths)-1, 0)
+}
+
+// formatRow - isi dipotong sesuai lebar kolom; angka rata kanan kalau alignNumbers
+func formatRow(cells []string, widths []int, alignNumbers bool) string {
+	var sb strings.Builder
+	for i, width := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		runes := []rune(cell)
+		if len(runes) > width {
+			runes = append(runes[:width-1], '~')
+		}
+		padding := strings.Repeat(" ", width-len(runes))
+
+		if i > 0 {
+			sb.WriteString(strings.Repeat(" ", columnGap))
+		}
+		if _, err := strconv.ParseFloat(cell, 64); alignNumbers && err == nil {
+			sb.WriteString(padding + string(runes))
+		} else {
+			sb.WriteString(string(runes) + padding)
+		}
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// escape - teks untuk string literal PDF (WinAnsi); karakter di luar Latin-1 diganti "?"
+func escape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 32:
+			sb.WriteByte(' ')
+		case r < 128:
+			sb.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir/models"
+	"time"
+)
+
+type BatchRepository struct {
+	db *sql.DB
+}
+
+func NewBatchRepository(db *sql.DB) *BatchRepository {
+	return &BatchRepository{db: db}
+}
+
+const batchColumns = `b.id, b.product_id, p.name, b.batch_number, b.expiry_date, b.received_quantity, b.quantity,
+	                  (b.expiry_date - CURRENT_DATE), b.created_at`
+
+// GetExpiring - batch yang masih ada stoknya dan kedaluwarsa dalam N hari (termasuk yang sudah lewat)
+func (repo *BatchRepository) GetExpiring(days int) ([]models.ProductBatch, error) {
+	query := `SELECT ` + batchColumns + `
+	          FROM product_batches b
+	          JOIN products p ON b.product_id = p.id
+	          WHERE b.quantity > 0 AND b.expiry_date <= CURRENT_DATE + $1::int
+	          ORDER BY b.expiry_date, b.id`
+
+	return repo.query(query, days)
+}
+
+// GetByProduct - semua batch yang masih ada stoknya untuk satu produk, urut FEFO
+func (repo *BatchRepository) GetByProduct(productID int) ([]models.ProductBatch, error) {
+	query := `SELECT ` + batchColumns + `
+	          FROM product_batches b
+	          JOIN products p ON b.product_id = p.id
+	          WHERE b.product_id = $1 AND b.quantity > 0
+	          ORDER BY b.expiry_date, b.id`
+
+	return repo.query(query, productID)
+}
+
+func (repo *BatchRepository) query(query string, args ...interface{}) ([]models.ProductBatch, error) {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batches := make([]models.ProductBatch, 0)
+	for rows.Next() {
+		var b models.ProductBatch
+		var batchNumber sql.NullString
+		var expiry time.Time
+		err := rows.Scan(&b.ID, &b.ProductID, &b.ProductName, &batchNumber, &expiry,
+			&b.ReceivedQuantity, &b.Quantity, &b.DaysUntilExpiry, &b.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		b.BatchNumber = batchNumber.String
+		b.ExpiryDate = expiry.Format("2006-01-02")
+		b.Expired = b.DaysUntilExpiry < 0
+		batches = append(batches, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+// batchTake - porsi batch yang diambil saat pengurangan stok FEFO
+type batchTake struct {
+	batchID     int
+	batchNumber string
+	expiryDate  string
+	quantity    int
+}
+
+// createBatch - daftarkan batch baru dari penerimaan barang; locationID 0 berarti tanpa lokasi
+func createBatch(tx *sql.Tx, productID, locationID, receiptLineID, quantity int, batchNumber, expiryDate string) (int, error) {
+	expiry, err := time.Parse("2006-01-02", expiryDate)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expiry date for product id %d: %q", productID, expiryDate)
+	}
+
+	var id int
+	err = tx.QueryRow(`INSERT INTO product_batches (product_id, location_id, goods_receipt_line_id, batch_number, expiry_date, received_quantity, quantity)
+	                   VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), NULLIF($4, ''), $5, $6, $6) RETURNING id`,
+		productID, locationID, receiptLineID, batchNumber, expiry.Format("2006-01-02"), quantity).Scan(&id)
+	return id, err
+}
+
+// deductBatchesFEFO - kurangi stok batch first-expired-first-out; batch yang sudah kedaluwarsa tidak boleh dijual.
+// locationID 0 berarti batch dari lokasi mana saja.
+func deductBatchesFEFO(tx *sql.Tx, productID, locationID, quantity int) ([]batchTake, error) {
+	rows, err := tx.Query(`SELECT id, COALESCE(batch_number, ''), expiry_date, quantity
+	                       FROM product_batches
+	                       WHERE product_id = $1 AND quantity > 0 AND expiry_date >= CURRENT_DATE
+	                         AND ($2::int = 0 OR location_id = $2::int)
+	                       ORDER BY expiry_date, id
+	                       FOR UPDATE`, productID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]batchTake, 0)
+	available := 0
+	for rows.Next() {
+		var b batchTake
+		var expiry time.Time
+		if err := rows.Scan(&b.batchID, &b.batchNumber, &expiry, &b.quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		b.expiryDate = expiry.Format("2006-01-02")
+		batches = append(batches, b)
+		available += b.quantity
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if available < quantity {
+		return nil, fmt.Errorf("insufficient stock for product id %d: requested %d, available %d (unexpired batches)", productID, quantity, available)
+	}
+
+	taken := make([]batchTake, 0)
+	remaining := quantity
+	for _, b := range batches {
+		if remaining == 0 {
+			break
+		}
+		take := min(b.quantity, remaining)
+		_, err := tx.Exec("UPDATE product_batches SET quantity = quantity - $1 WHERE id = $2", take, b.batchID)
+		if err != nil {
+			return nil, err
+		}
+		b.quantity = take
+		taken = append(taken, b)
+		remaining -= take
+	}
+
+	return taken, nil
+}
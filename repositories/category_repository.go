@@ -3,6 +3,7 @@ package repositories
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"kasir/models"
 )
 
@@ -14,8 +15,46 @@ func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
-func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
-	query := "SELECT id, name, description FROM categories ORDER BY id"
+// categoryTreeLock - key advisory lock untuk perubahan struktur pohon kategori
+const categoryTreeLock = 7_410_001
+
+const categoryColumns = "id, name, description, parent_id, archived_at"
+
+func scanCategory(row rowScanner) (*models.Category, error) {
+	var c models.Category
+	var description sql.NullString
+	var parentID sql.NullInt64
+	var archivedAt sql.NullTime
+	err := row.Scan(&c.ID, &c.Name, &description, &parentID, &archivedAt)
+	if err != nil {
+		return nil, err
+	}
+	c.Description = description.String
+	c.ParentID = nullIntPtr(parentID)
+	if archivedAt.Valid {
+		c.ArchivedAt = &archivedAt.Time
+	}
+	return &c, nil
+}
+
+// categorySubtree - subquery id kategori beserta seluruh turunannya, untuk filter produk by kategori.
+// placeholder adalah parameter id kategori root, mis. "$2".
+func categorySubtree(placeholder string) string {
+	return `(WITH RECURSIVE subtree AS (
+	             SELECT id FROM categories WHERE id = ` + placeholder + `::int
+	             UNION
+	             SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+	         ) SELECT id FROM subtree)`
+}
+
+// GetAll - list kategori; kategori yang diarsipkan hanya ikut kalau includeArchived
+func (repo *CategoryRepository) GetAll(includeArchived bool) ([]models.Category, error) {
+	query := "SELECT " + categoryColumns + " FROM categories"
+	if !includeArchived {
+		query += " WHERE archived_at IS NULL"
+	}
+	query += " ORDER BY id"
+
 	rows, err := repo.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -24,29 +63,34 @@ func (repo *CategoryRepository) GetAll() ([]models.Category, error) {
 
 	categories := make([]models.Category, 0)
 	for rows.Next() {
-		var c models.Category
-		var description sql.NullString
-		err := rows.Scan(&c.ID, &c.Name, &description)
+		c, err := scanCategory(rows)
 		if err != nil {
 			return nil, err
 		}
-		categories = append(categories, c)
+		categories = append(categories, *c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return categories, nil
 }
 
 func (repo *CategoryRepository) Create(category *models.Category) error {
-	query := "INSERT INTO categories (name, description) VALUES ($1, $2) RETURNING id"
-	err := repo.db.QueryRow(query, category.Name, category.Description).Scan(&category.ID)
+	if category.ParentID != nil {
+		if err := validateCategoryParent(repo.db, 0, *category.ParentID); err != nil {
+			return err
+		}
+	}
+
+	query := "INSERT INTO categories (name, description, parent_id) VALUES ($1, $2, $3) RETURNING id"
+	err := repo.db.QueryRow(query, category.Name, category.Description, category.ParentID).Scan(&category.ID)
 	return err
 }
 
 func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
-	query := "SELECT id, name, description FROM categories WHERE id = $1"
-
-	var c models.Category
-	err := repo.db.QueryRow(query, id).Scan(&c.ID, &c.Name, &c.Description)
+	c, err := scanCategory(repo.db.QueryRow("SELECT "+categoryColumns+" FROM categories WHERE id = $1", id))
 	if err == sql.ErrNoRows {
 		return nil, errors.New("kategori tidak ditemukan")
 	}
@@ -54,12 +98,33 @@ func (repo *CategoryRepository) GetByID(id int) (*models.Category, error) {
 		return nil, err
 	}
 
-	return &c, nil
+	return c, nil
 }
 
-func (repo *CategoryRepository) Update(category *models.Category) error {
-	query := "UPDATE categories SET name = $1, description = $2 WHERE id = $3"
-	result, err := repo.db.Exec(query, category.Name, category.Description, category.ID)
+// Update - ubah kategori. Kalau ParentSet, parent_id diganti dan kategori dipindah beserta seluruh
+// subtree-nya; produk tetap di kategori masing-masing. Parent tidak boleh kategori itu sendiri atau turunannya.
+func (repo *CategoryRepository) Update(id int, category models.CategoryUpdate) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if category.ParentSet && category.ParentID != nil {
+		// Lock level pohon: dua pemindahan bersamaan (A ke bawah B, B ke bawah A) masing-masing
+		// lolos cek siklus kalau hanya baris kategori yang dikunci
+		if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", categoryTreeLock); err != nil {
+			return err
+		}
+		if err := validateCategoryParent(tx, id, *category.ParentID); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE categories SET name = $1, description = $2,
+	                 parent_id = CASE WHEN $4::boolean THEN $3::int ELSE parent_id END
+	          WHERE id = $5`
+	result, err := tx.Exec(query, category.Name, category.Description, category.ParentID, category.ParentSet, id)
 	if err != nil {
 		return err
 	}
@@ -73,24 +138,256 @@ func (repo *CategoryRepository) Update(category *models.Category) error {
 		return errors.New("kategori tidak ditemukan")
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+// Archive - arsipkan kategori (soft delete) dalam satu transaksi bersama penanganan produknya.
+//
+// Kategori yang masih punya subkategori aktif tidak bisa diarsipkan, subkategori harus
+// dipindah atau diarsipkan dulu supaya tidak ada cabang aktif di bawah kategori yang diarsipkan.
+// Kalau masih ada produk (termasuk yang diarsipkan), opts harus memilih ReassignTo atau
+// Uncategorize; kalau tidak, dikembalikan *models.CategoryInUseError. Mengembalikan jumlah
+// produk yang dipindah/dilepas.
+func (repo *CategoryRepository) Archive(id int, opts models.CategoryArchiveOptions) (int, error) {
+	if opts.ReassignTo > 0 && opts.Uncategorize {
+		return 0, errors.New("invalid options: choose either reassign_to or uncategorize, not both")
+	}
+	if opts.ReassignTo == id {
+		return 0, fmt.Errorf("invalid reassign_to: cannot reassign products of category %d to itself", id)
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow("SELECT TRUE FROM categories WHERE id = $1 FOR UPDATE", id).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return 0, errors.New("kategori tidak ditemukan")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var activeChildren int
+	err = tx.QueryRow("SELECT COUNT(*) FROM categories WHERE parent_id = $1 AND archived_at IS NULL", id).
+		Scan(&activeChildren)
+	if err != nil {
+		return 0, err
+	}
+	if activeChildren > 0 {
+		return 0, fmt.Errorf("category %d cannot be archived: it has %d active subcategories", id, activeChildren)
+	}
+
+	var productCount int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM products WHERE category_id = $1", id).Scan(&productCount); err != nil {
+		return 0, err
+	}
+
+	if productCount > 0 {
+		var target *int
+		switch {
+		case opts.ReassignTo > 0:
+			var archived bool
+			err := tx.QueryRow("SELECT archived_at IS NOT NULL FROM categories WHERE id = $1 FOR SHARE", opts.ReassignTo).Scan(&archived)
+			if err == sql.ErrNoRows {
+				return 0, fmt.Errorf("target category %d not found", opts.ReassignTo)
+			}
+			if err != nil {
+				return 0, err
+			}
+			if archived {
+				return 0, fmt.Errorf("invalid reassign_to: category %d is archived", opts.ReassignTo)
+			}
+			target = &opts.ReassignTo
+		case opts.Uncategorize:
+		default:
+			return 0, &models.CategoryInUseError{CategoryID: id, ProductCount: productCount}
+		}
+
+		_, err := tx.Exec("UPDATE products SET category_id = $1 WHERE category_id = $2", target, id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE categories SET archived_at = COALESCE(archived_at, NOW()) WHERE id = $1", id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return productCount, nil
 }
 
-func (repo *CategoryRepository) Delete(id int) error {
-	query := "DELETE FROM categories WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+// Restore - kembalikan kategori yang diarsipkan; parent-nya harus aktif
+func (repo *CategoryRepository) Restore(id int) error {
+	var parentArchived bool
+	err := repo.db.QueryRow(`SELECT COALESCE(p.archived_at IS NOT NULL, FALSE)
+	                         FROM categories c
+	                         LEFT JOIN categories p ON c.parent_id = p.id
+	                         WHERE c.id = $1`, id).Scan(&parentArchived)
+	if err == sql.ErrNoRows {
+		return errors.New("kategori tidak ditemukan")
+	}
 	if err != nil {
 		return err
 	}
+	if parentArchived {
+		return fmt.Errorf("category %d cannot be restored while its parent category is archived", id)
+	}
 
-	rows, err := result.RowsAffected()
+	return setArchived(repo.db, "categories", id, false, "kategori tidak ditemukan")
+}
+
+// validateCategoryParent - parent harus ada, aktif, dan bukan kategori id sendiri atau turunannya (id 0 = kategori baru)
+func validateCategoryParent(q queryRower, id, parentID int) error {
+	if parentID == id {
+		return fmt.Errorf("invalid parent_id: category %d cannot be its own parent", id)
+	}
+
+	var archived bool
+	err := q.QueryRow("SELECT archived_at IS NOT NULL FROM categories WHERE id = $1", parentID).Scan(&archived)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("parent category %d not found", parentID)
+	}
 	if err != nil {
 		return err
 	}
+	if archived {
+		return fmt.Errorf("invalid parent_id: category %d is archived", parentID)
+	}
 
-	if rows == 0 {
-		return errors.New("kategori tidak ditemukan")
+	if id == 0 {
+		return nil
+	}
+
+	var isDescendant bool
+	err = q.QueryRow(`SELECT $2::int IN `+categorySubtree("$1"), id, parentID).Scan(&isDescendant)
+	if err != nil {
+		return err
+	}
+	if isDescendant {
+		return fmt.Errorf("invalid parent_id: category %d is a descendant of category %d", parentID, id)
 	}
 
 	return nil
 }
+
+// Merge - gabungkan kategori sumber ke target secara atomik: semua produk (termasuk yang diarsipkan)
+// dan subkategori langsung dipindah ke target, lalu sumber diarsipkan. Target tidak boleh sumber itu
+// sendiri atau turunannya. Kalau dryRun, perubahan di-rollback dan hasilnya menjadi preview.
+func (repo *CategoryRepository) Merge(sourceID, targetID int, dryRun bool) (*models.CategoryMergeResult, error) {
+	if targetID <= 0 {
+		return nil, errors.New("target_id is required")
+	}
+	if sourceID == targetID {
+		return nil, fmt.Errorf("invalid target_id: cannot merge category %d into itself", sourceID)
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", categoryTreeLock); err != nil {
+		return nil, err
+	}
+
+	var sourceArchived bool
+	err = tx.QueryRow("SELECT archived_at IS NOT NULL FROM categories WHERE id = $1 FOR UPDATE", sourceID).Scan(&sourceArchived)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("kategori tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sourceArchived {
+		return nil, fmt.Errorf("category %d cannot be merged because it is archived", sourceID)
+	}
+
+	var targetArchived, targetInSubtree bool
+	err = tx.QueryRow(`SELECT archived_at IS NOT NULL, id IN `+categorySubtree("$2")+`
+	                   FROM categories WHERE id = $1`, targetID, sourceID).Scan(&targetArchived, &targetInSubtree)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("target category %d not found", targetID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if targetArchived {
+		return nil, fmt.Errorf("invalid target_id: category %d is archived", targetID)
+	}
+	if targetInSubtree {
+		return nil, fmt.Errorf("invalid target_id: category %d is a subcategory of category %d", targetID, sourceID)
+	}
+
+	result := &models.CategoryMergeResult{
+		SourceID:      sourceID,
+		TargetID:      targetID,
+		DryRun:        dryRun,
+		Products:      make([]models.CategoryMergeProduct, 0),
+		Subcategories: make([]int, 0),
+	}
+
+	rows, err := tx.Query("SELECT id, name FROM products WHERE category_id = $1 ORDER BY id FOR UPDATE", sourceID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var p models.CategoryMergeProduct
+		if err := rows.Scan(&p.ID, &p.Name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		result.Products = append(result.Products, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query("SELECT id FROM categories WHERE parent_id = $1 ORDER BY id", sourceID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		result.Subcategories = append(result.Subcategories, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE products SET category_id = $1 WHERE category_id = $2", targetID, sourceID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("UPDATE categories SET parent_id = $1 WHERE parent_id = $2", targetID, sourceID); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("UPDATE categories SET archived_at = NOW() WHERE id = $1", sourceID); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+
+	return result, nil
+}
@@ -0,0 +1,232 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// categoryFixture - kategori dan produk test dengan prefix nama unik, dihapus lagi di akhir test
+type categoryFixture struct {
+	t          *testing.T
+	db         *sql.DB
+	prefix     string
+	categories *CategoryRepository
+	products   *ProductRepository
+	productIDs []int
+}
+
+func newCategoryFixture(t *testing.T) *categoryFixture {
+	db := testDB(t)
+	f := &categoryFixture{
+		t:          t,
+		db:         db,
+		prefix:     fmt.Sprintf("test-%d-", time.Now().UnixNano()),
+		categories: NewCategoryRepository(db),
+		products:   NewProductRepository(db),
+	}
+	t.Cleanup(f.cleanup)
+	return f
+}
+
+func (f *categoryFixture) category(name string, parentID *int) int {
+	f.t.Helper()
+	c := &models.Category{Name: f.prefix + name, ParentID: parentID}
+	if err := f.categories.Create(c); err != nil {
+		f.t.Fatalf("create category %s: %v", name, err)
+	}
+	return c.ID
+}
+
+func (f *categoryFixture) product(name string, categoryID int) int {
+	f.t.Helper()
+	p := &models.Product{Name: f.prefix + name, Price: 1000, Category: &models.Category{ID: categoryID}}
+	if err := f.products.Create(p); err != nil {
+		f.t.Fatalf("create product %s: %v", name, err)
+	}
+	f.productIDs = append(f.productIDs, p.ID)
+	return p.ID
+}
+
+func (f *categoryFixture) parentOf(id int) *int {
+	f.t.Helper()
+	c, err := f.categories.GetByID(id)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return c.ParentID
+}
+
+func (f *categoryFixture) cleanup() {
+	for _, id := range f.productIDs {
+		for _, table := range []string{"product_price_history", "product_cost_history", "stock_movements"} {
+			f.db.Exec("DELETE FROM "+table+" WHERE product_id = $1", id)
+		}
+		f.db.Exec("DELETE FROM products WHERE id = $1", id)
+	}
+	f.db.Exec("UPDATE categories SET parent_id = NULL WHERE name LIKE $1", f.prefix+"%")
+	f.db.Exec("DELETE FROM categories WHERE name LIKE $1", f.prefix+"%")
+}
+
+func TestCategoryRepositoryMoveSubtree(t *testing.T) {
+	f := newCategoryFixture(t)
+	food := f.category("food", nil)
+	drinks := f.category("drinks", nil)
+	coffee := f.category("coffee", &food)
+	espresso := f.category("espresso", &coffee)
+
+	err := f.categories.Update(coffee, models.CategoryUpdate{Name: f.prefix + "coffee", ParentID: &drinks, ParentSet: true})
+	if err != nil {
+		t.Fatalf("move coffee under drinks: %v", err)
+	}
+
+	if got := f.parentOf(coffee); got == nil || *got != drinks {
+		t.Errorf("coffee parent = %v, want %d", got, drinks)
+	}
+	// turunan ikut pindah karena parent-nya tidak berubah
+	if got := f.parentOf(espresso); got == nil || *got != coffee {
+		t.Errorf("espresso parent = %v, want %d", got, coffee)
+	}
+
+	var inDrinks bool
+	err = f.db.QueryRow(`SELECT $2::int IN `+categorySubtree("$1"), drinks, espresso).Scan(&inDrinks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inDrinks {
+		t.Error("espresso is not in the drinks subtree after the move")
+	}
+}
+
+func TestCategoryRepositoryUpdateKeepsParentUnlessSet(t *testing.T) {
+	f := newCategoryFixture(t)
+	root := f.category("root", nil)
+	child := f.category("child", &root)
+
+	err := f.categories.Update(child, models.CategoryUpdate{Name: f.prefix + "renamed", Description: "baru"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.parentOf(child); got == nil || *got != root {
+		t.Errorf("parent after rename = %v, want %d", got, root)
+	}
+
+	err = f.categories.Update(child, models.CategoryUpdate{Name: f.prefix + "renamed", ParentSet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.parentOf(child); got != nil {
+		t.Errorf("parent after parent_id null = %d, want root (nil)", *got)
+	}
+}
+
+func TestCategoryRepositoryRejectsCycles(t *testing.T) {
+	f := newCategoryFixture(t)
+	root := f.category("root", nil)
+	child := f.category("child", &root)
+	grandchild := f.category("grandchild", &child)
+
+	tests := []struct {
+		name     string
+		id       int
+		parentID int
+		want     string
+	}{
+		{"self as parent", root, root, "cannot be its own parent"},
+		{"child as parent", root, child, "is a descendant of category"},
+		{"grandchild as parent", root, grandchild, "is a descendant of category"},
+		{"grandchild as parent of child", child, grandchild, "is a descendant of category"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parentID := tt.parentID
+			err := f.categories.Update(tt.id, models.CategoryUpdate{Name: f.prefix + "moved", ParentID: &parentID, ParentSet: true})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if got := f.parentOf(root); got != nil {
+		t.Errorf("root parent = %d after rejected moves, want nil", *got)
+	}
+}
+
+func TestCategoryRepositoryArchiveInUse(t *testing.T) {
+	f := newCategoryFixture(t)
+	parent := f.category("parent", nil)
+	f.category("child", &parent)
+	stocked := f.category("stocked", nil)
+	f.product("product", stocked)
+
+	_, err := f.categories.Archive(parent, models.CategoryArchiveOptions{})
+	if err == nil || !strings.Contains(err.Error(), "active subcategories") {
+		t.Errorf("archive with subcategories: err = %v, want active subcategories error", err)
+	}
+
+	_, err = f.categories.Archive(stocked, models.CategoryArchiveOptions{})
+	var inUse *models.CategoryInUseError
+	if !errors.As(err, &inUse) {
+		t.Fatalf("archive with products: err = %v, want *models.CategoryInUseError", err)
+	}
+	if inUse.CategoryID != stocked || inUse.ProductCount != 1 {
+		t.Errorf("CategoryInUseError = %+v, want category %d with 1 product", inUse, stocked)
+	}
+
+	for _, id := range []int{parent, stocked} {
+		c, err := f.categories.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.ArchivedAt != nil {
+			t.Errorf("category %d archived despite the error", id)
+		}
+	}
+}
+
+func TestCategoryRepositoryProductFilterIncludesDescendants(t *testing.T) {
+	f := newCategoryFixture(t)
+	drinks := f.category("drinks", nil)
+	coffee := f.category("coffee", &drinks)
+	espresso := f.category("espresso", &coffee)
+	food := f.category("food", nil)
+
+	inDrinks := f.product("tea", drinks)
+	inCoffee := f.product("latte", coffee)
+	inEspresso := f.product("doppio", espresso)
+	f.product("bread", food)
+
+	tests := []struct {
+		name       string
+		categoryID int
+		want       []int
+	}{
+		{"root includes all descendants", drinks, []int{inDrinks, inCoffee, inEspresso}},
+		{"middle includes its subtree", coffee, []int{inCoffee, inEspresso}},
+		{"leaf only", espresso, []int{inEspresso}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := f.products.GetAll(models.ProductFilter{Name: f.prefix, CategoryID: tt.categoryID, PageSize: 100})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[int]bool)
+			for _, p := range page.Data {
+				got[p.ID] = true
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d products, want %d", len(got), len(tt.want))
+			}
+			for _, id := range tt.want {
+				if !got[id] {
+					t.Errorf("product %d missing from category %d", id, tt.categoryID)
+				}
+			}
+		})
+	}
+}
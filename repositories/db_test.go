@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir/database"
+	"os"
+	"testing"
+)
+
+// testDB - koneksi ke database yang sudah dimigrasi (semua file database/migrations).
+// Test dilewati kalau TEST_DB_CONN tidak diset; jangan arahkan ke database produksi.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn := os.Getenv("TEST_DB_CONN")
+	if conn == "" {
+		t.Skip("TEST_DB_CONN not set")
+	}
+	db, err := database.InitDB(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
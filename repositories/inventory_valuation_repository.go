@@ -0,0 +1,256 @@
+package repositories
+
+import (
+	"fmt"
+	"kasir/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// receiptLayer - satu penerimaan PO untuk perhitungan harga pokok
+type receiptLayer struct {
+	quantity int
+	unitCost int
+}
+
+// GetInventoryValuation - nilai persediaan per produk, kategori dan lokasi.
+// asOf nol = stok saat ini; selain itu stok direkonstruksi dengan membatalkan mutasi
+// stock_movements sejak asOf dari stok sekarang. Harga pokok per unit sama untuk semua lokasi.
+//
+// Metode average memakai HPP rata-rata perpetual (products.cost_price, atau riwayatnya pada asOf)
+// yang juga dipakai checkout untuk COGS, jadi nilai persediaan dan COGS berasal dari rata-rata yang sama.
+func (repo *ReportRepository) GetInventoryValuation(method string, asOf time.Time) (*models.InventoryValuation, error) {
+	if method != models.ValuationAverage && method != models.ValuationFIFO {
+		return nil, fmt.Errorf("invalid method: %q (allowed: average, fifo)", method)
+	}
+
+	// Tanpa asOf, cutoff jauh di masa depan: semua penerimaan dihitung dan tidak ada mutasi yang dibatalkan
+	cutoff := asOf
+	if cutoff.IsZero() {
+		cutoff = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	lines, err := repo.inventoryLines(asOf, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	layers := make(map[int][]receiptLayer)
+	if method == models.ValuationFIFO {
+		if layers, err = repo.receiptLayers(cutoff); err != nil {
+			return nil, err
+		}
+	}
+
+	valuation := &models.InventoryValuation{
+		Method:     method,
+		Products:   make([]models.InventoryValuationLine, 0, len(lines)),
+		Categories: make([]models.InventoryValuationGroup, 0),
+		Locations:  make([]models.InventoryValuationGroup, 0),
+	}
+
+	unitCosts := make(map[int]float64, len(lines))
+	categories := make(map[int]*models.InventoryValuationGroup)
+	for _, line := range lines {
+		line.UnitCost = unitCost(method, line.Quantity, layers[line.ProductID], line.UnitCost)
+		line.Value = int64(math.Round(line.UnitCost * float64(line.Quantity)))
+		line.RetailValue = int64(line.RetailPrice) * int64(line.Quantity)
+		unitCosts[line.ProductID] = line.UnitCost
+
+		valuation.TotalQuantity += int64(line.Quantity)
+		valuation.TotalValue += line.Value
+		valuation.TotalRetailValue += line.RetailValue
+		valuation.Products = append(valuation.Products, line)
+
+		group, ok := categories[line.CategoryID]
+		if !ok {
+			group = &models.InventoryValuationGroup{ID: line.CategoryID, Name: line.CategoryName}
+			categories[line.CategoryID] = group
+		}
+		group.Quantity += int64(line.Quantity)
+		group.Value += line.Value
+		group.RetailValue += line.RetailValue
+	}
+
+	for _, group := range categories {
+		valuation.Categories = append(valuation.Categories, *group)
+	}
+	sort.Slice(valuation.Categories, func(i, j int) bool {
+		if valuation.Categories[i].Value != valuation.Categories[j].Value {
+			return valuation.Categories[i].Value > valuation.Categories[j].Value
+		}
+		return valuation.Categories[i].ID < valuation.Categories[j].ID
+	})
+	sort.SliceStable(valuation.Products, func(i, j int) bool {
+		return valuation.Products[i].Value > valuation.Products[j].Value
+	})
+
+	valuation.Locations, err = repo.locationValuation(cutoff, lines, unitCosts)
+	if err != nil {
+		return nil, err
+	}
+
+	return valuation, nil
+}
+
+// unitCost - harga pokok per unit. averageCost = HPP rata-rata perpetual pada tanggal penilaian.
+// Average langsung memakai averageCost. FIFO menilai stok dari penerimaan paling baru (layers urut
+// dari yang terbaru); stok yang melebihi semua penerimaan (stok awal, penyesuaian manual) dinilai
+// dengan averageCost pada tanggal yang sama, bukan HPP hari ini.
+func unitCost(method string, quantity int, layers []receiptLayer, averageCost float64) float64 {
+	if method == models.ValuationAverage || len(layers) == 0 || quantity <= 0 {
+		return averageCost
+	}
+
+	var value float64
+	remaining := quantity
+	for _, l := range layers {
+		take := min(remaining, l.quantity)
+		value += float64(take) * float64(l.unitCost)
+		remaining -= take
+		if remaining == 0 {
+			break
+		}
+	}
+	value += float64(remaining) * averageCost
+	return math.Round(value/float64(quantity)*100) / 100
+}
+
+// inventoryLines - stok dan harga jual per produk pada waktu cutoff (produk dengan stok nol dilewati).
+// UnitCost diisi HPP rata-rata: cost_price sekarang, atau nilai product_cost_history yang berlaku
+// pada asOf (sebelum riwayat pertama dipakai nilai riwayat paling awal).
+func (repo *ReportRepository) inventoryLines(asOf, cutoff time.Time) ([]models.InventoryValuationLine, error) {
+	costColumn := "p.cost_price"
+	if !asOf.IsZero() {
+		costColumn = `COALESCE((SELECT h.cost_price FROM product_cost_history h
+		                        WHERE h.product_id = p.id AND h.changed_at < $1
+		                        ORDER BY h.changed_at DESC, h.id DESC LIMIT 1),
+		                       (SELECT h.cost_price FROM product_cost_history h
+		                        WHERE h.product_id = p.id
+		                        ORDER BY h.changed_at, h.id LIMIT 1),
+		                       p.cost_price)`
+	}
+
+	query := `
+		SELECT p.id, COALESCE(p.sku, ''), p.name, COALESCE(c.id, 0), COALESCE(c.name, 'Uncategorized'),
+		       p.stock - COALESCE((SELECT SUM(m.quantity) FROM stock_movements m
+		                           WHERE m.product_id = p.id AND m.location_id IS NULL AND m.created_at >= $1), 0),
+		       COALESCE((SELECT h.new_price FROM product_price_history h
+		                 WHERE h.product_id = p.id AND h.changed_at < $1
+		                 ORDER BY h.changed_at DESC, h.id DESC LIMIT 1), p.price),
+		       ` + costColumn + `
+		FROM products p
+		LEFT JOIN categories c ON p.category_id = c.id
+		ORDER BY p.name, p.id`
+
+	rows, err := repo.db.Query(query, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]models.InventoryValuationLine, 0)
+	for rows.Next() {
+		var line models.InventoryValuationLine
+		var costPrice int
+		err := rows.Scan(&line.ProductID, &line.SKU, &line.Name, &line.CategoryID, &line.CategoryName,
+			&line.Quantity, &line.RetailPrice, &costPrice)
+		if err != nil {
+			return nil, err
+		}
+		if line.Quantity == 0 {
+			continue
+		}
+		line.UnitCost = float64(costPrice)
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+// receiptLayers - penerimaan PO sebelum cutoff per produk, dari yang terbaru
+func (repo *ReportRepository) receiptLayers(cutoff time.Time) (map[int][]receiptLayer, error) {
+	rows, err := repo.db.Query(`
+		SELECT grl.product_id, grl.quantity, grl.unit_cost
+		FROM goods_receipt_lines grl
+		JOIN goods_receipts gr ON grl.goods_receipt_id = gr.id
+		WHERE gr.received_at < $1
+		ORDER BY grl.product_id, gr.received_at DESC, grl.id DESC`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	layers := make(map[int][]receiptLayer)
+	for rows.Next() {
+		var productID int
+		var l receiptLayer
+		if err := rows.Scan(&productID, &l.quantity, &l.unitCost); err != nil {
+			return nil, err
+		}
+		layers[productID] = append(layers[productID], l)
+	}
+
+	return layers, rows.Err()
+}
+
+// locationValuation - nilai persediaan per lokasi pada waktu cutoff. Stok yang tidak tercatat di
+// lokasi mana pun (toko tanpa lokasi, barang dalam perjalanan transfer) masuk grup id 0.
+func (repo *ReportRepository) locationValuation(cutoff time.Time, lines []models.InventoryValuationLine, unitCosts map[int]float64) ([]models.InventoryValuationGroup, error) {
+	rows, err := repo.db.Query(`
+		SELECT s.product_id, l.id, l.name, SUM(s.quantity)::int
+		FROM (
+			SELECT product_id, location_id, quantity FROM product_stocks
+			UNION ALL
+			SELECT product_id, location_id, -quantity FROM stock_movements
+			WHERE location_id IS NOT NULL AND created_at >= $1
+		) s
+		JOIN locations l ON s.location_id = l.id
+		GROUP BY s.product_id, l.id, l.name
+		HAVING SUM(s.quantity) <> 0
+		ORDER BY l.id, s.product_id`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	retailPrices := make(map[int]int, len(lines))
+	unassigned := make(map[int]int, len(lines))
+	for _, line := range lines {
+		retailPrices[line.ProductID] = line.RetailPrice
+		unassigned[line.ProductID] = line.Quantity
+	}
+
+	groups := make([]models.InventoryValuationGroup, 0)
+	for rows.Next() {
+		var productID, quantity int
+		var location models.InventoryValuationGroup
+		if err := rows.Scan(&productID, &location.ID, &location.Name, &quantity); err != nil {
+			return nil, err
+		}
+		if len(groups) == 0 || groups[len(groups)-1].ID != location.ID {
+			groups = append(groups, location)
+		}
+		group := &groups[len(groups)-1]
+		group.Quantity += int64(quantity)
+		group.Value += int64(math.Round(unitCosts[productID] * float64(quantity)))
+		group.RetailValue += int64(retailPrices[productID]) * int64(quantity)
+		unassigned[productID] -= quantity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rest := models.InventoryValuationGroup{ID: 0, Name: "Unassigned"}
+	for _, line := range lines {
+		quantity := unassigned[line.ProductID]
+		rest.Quantity += int64(quantity)
+		rest.Value += int64(math.Round(unitCosts[line.ProductID] * float64(quantity)))
+		rest.RetailValue += int64(line.RetailPrice) * int64(quantity)
+	}
+	if rest.Quantity != 0 {
+		groups = append(groups, rest)
+	}
+
+	return groups, nil
+}
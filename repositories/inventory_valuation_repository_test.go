@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"kasir/models"
+	"testing"
+)
+
+func TestUnitCost(t *testing.T) {
+	// urut dari penerimaan paling baru
+	layers := []receiptLayer{{quantity: 10, unitCost: 1200}, {quantity: 20, unitCost: 1000}}
+
+	tests := []struct {
+		name        string
+		method      string
+		quantity    int
+		layers      []receiptLayer
+		averageCost float64
+		want        float64
+	}{
+		{"average ignores layers", models.ValuationAverage, 15, layers, 1050.5, 1050.5},
+		{"fifo within newest layer", models.ValuationFIFO, 5, layers, 900, 1200},
+		{"fifo exactly the newest layer", models.ValuationFIFO, 10, layers, 900, 1200},
+		{"fifo across layers", models.ValuationFIFO, 15, layers, 900, 1133.33},
+		{"fifo all layers", models.ValuationFIFO, 30, layers, 900, 1066.67},
+		{"fifo excess valued at average cost", models.ValuationFIFO, 40, layers, 900, 1025},
+		{"fifo without receipts", models.ValuationFIFO, 8, nil, 900, 900},
+		{"fifo zero stock", models.ValuationFIFO, 0, layers, 900, 900},
+		{"fifo negative stock", models.ValuationFIFO, -3, layers, 900, 900},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unitCost(tt.method, tt.quantity, tt.layers, tt.averageCost); got != tt.want {
+				t.Errorf("unitCost(%s, %d) = %v, want %v", tt.method, tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir/models"
+)
+
+type LocationRepository struct {
+	db *sql.DB
+}
+
+func NewLocationRepository(db *sql.DB) *LocationRepository {
+	return &LocationRepository{db: db}
+}
+
+func (repo *LocationRepository) GetAll() ([]models.Location, error) {
+	query := "SELECT id, name, type, address FROM locations ORDER BY id"
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := make([]models.Location, 0)
+	for rows.Next() {
+		var l models.Location
+		var address sql.NullString
+		err := rows.Scan(&l.ID, &l.Name, &l.Type, &address)
+		if err != nil {
+			return nil, err
+		}
+		l.Address = address.String
+		locations = append(locations, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return locations, nil
+}
+
+func (repo *LocationRepository) Create(location *models.Location) error {
+	if err := validateLocationType(location); err != nil {
+		return err
+	}
+
+	query := "INSERT INTO locations (name, type, address) VALUES ($1, $2, $3) RETURNING id"
+	err := repo.db.QueryRow(query, location.Name, location.Type, location.Address).Scan(&location.ID)
+	return err
+}
+
+func (repo *LocationRepository) GetByID(id int) (*models.Location, error) {
+	query := "SELECT id, name, type, address FROM locations WHERE id = $1"
+
+	var l models.Location
+	var address sql.NullString
+	err := repo.db.QueryRow(query, id).Scan(&l.ID, &l.Name, &l.Type, &address)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("lokasi tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.Address = address.String
+
+	return &l, nil
+}
+
+func (repo *LocationRepository) Update(location *models.Location) error {
+	if err := validateLocationType(location); err != nil {
+		return err
+	}
+
+	query := "UPDATE locations SET name = $1, type = $2, address = $3 WHERE id = $4"
+	result, err := repo.db.Exec(query, location.Name, location.Type, location.Address, location.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("lokasi tidak ditemukan")
+	}
+
+	return nil
+}
+
+// GetStock - stok semua produk di satu lokasi
+func (repo *LocationRepository) GetStock(locationID int) ([]models.LocationStock, error) {
+	query := `SELECT ps.product_id, p.name, ps.location_id, ps.quantity
+	          FROM product_stocks ps
+	          JOIN products p ON ps.product_id = p.id
+	          WHERE ps.location_id = $1
+	          ORDER BY p.id`
+
+	rows, err := repo.db.Query(query, locationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stocks := make([]models.LocationStock, 0)
+	for rows.Next() {
+		var s models.LocationStock
+		err := rows.Scan(&s.ProductID, &s.ProductName, &s.LocationID, &s.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stocks, nil
+}
+
+// SetStock - stock opname: set jumlah stok produk di lokasi, selisihnya ikut mengubah total products.stock.
+// Produk track_expiry ditolak karena stoknya harus cocok dengan isi batch di lokasi tersebut.
+func (repo *LocationRepository) SetStock(locationID int, stocks []models.LocationStock) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM locations WHERE id = $1)", locationID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("lokasi tidak ditemukan")
+	}
+
+	for _, s := range stocks {
+		if s.Quantity < 0 {
+			return fmt.Errorf("invalid quantity for product %d: %d", s.ProductID, s.Quantity)
+		}
+
+		// Produk track_expiry dihitung per batch; opname total tanpa batch akan membuat FEFO tidak cocok
+		var trackExpiry bool
+		err := tx.QueryRow("SELECT track_expiry FROM products WHERE id = $1", s.ProductID).Scan(&trackExpiry)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("product id %d not found", s.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+		if trackExpiry {
+			return fmt.Errorf("cannot set stock for product id %d: product tracks expiry, adjust its batches instead", s.ProductID)
+		}
+
+		var current int
+		err = tx.QueryRow(`SELECT COALESCE((SELECT quantity FROM product_stocks
+		                                     WHERE product_id = $1 AND location_id = $2 FOR UPDATE), 0)`,
+			s.ProductID, locationID).Scan(&current)
+		if err != nil {
+			return err
+		}
+
+		delta := s.Quantity - current
+		if delta == 0 {
+			continue
+		}
+
+		if err := adjustLocationStock(tx, s.ProductID, locationID, delta); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE products SET stock = stock + $1 WHERE id = $2", delta, s.ProductID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func validateLocationType(location *models.Location) error {
+	if location.Type == "" {
+		location.Type = models.LocationOutlet
+	}
+	if location.Type != models.LocationOutlet && location.Type != models.LocationWarehouse {
+		return fmt.Errorf("invalid location type: %s", location.Type)
+	}
+	return nil
+}
+
+// adjustLocationStock - tambah/kurangi stok produk di satu lokasi tanpa cek ketersediaan
+func adjustLocationStock(tx *sql.Tx, productID, locationID, delta int) error {
+	_, err := tx.Exec(`INSERT INTO product_stocks (product_id, location_id, quantity) VALUES ($1, $2, $3)
+	                   ON CONFLICT (product_id, location_id) DO UPDATE SET quantity = product_stocks.quantity + EXCLUDED.quantity`,
+		productID, locationID, delta)
+	return err
+}
+
+// stockOverwriteBlocked - products.stock hanya boleh ditimpa langsung untuk produk tanpa batch expiry dan
+// tanpa stok per lokasi. Selain itu total harus tetap sama dengan isi batch/stok lokasi, jadi stok
+// diubah lewat receive PO, transfer atau stock opname per lokasi. Mengembalikan alasan penolakan,
+// kosong kalau stok boleh ditimpa.
+func stockOverwriteBlocked(q queryRower, productID int) (string, error) {
+	var trackExpiry, located bool
+	err := q.QueryRow(`SELECT track_expiry, EXISTS (SELECT 1 FROM product_stocks WHERE product_id = $1)
+	                   FROM products WHERE id = $1`, productID).Scan(&trackExpiry, &located)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("product id %d not found", productID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if trackExpiry {
+		return fmt.Sprintf("cannot change stock of product id %d directly: product tracks expiry, stock follows its batches", productID), nil
+	}
+	if located {
+		return fmt.Sprintf("cannot change stock of product id %d directly: product has stock per location, use stock opname", productID), nil
+	}
+	return "", nil
+}
+
+// deductLocationStock - kurangi stok produk di satu lokasi, gagal kalau stok di lokasi itu tidak cukup
+func deductLocationStock(tx *sql.Tx, productID, locationID, quantity int) error {
+	var available int
+	err := tx.QueryRow("SELECT quantity FROM product_stocks WHERE product_id = $1 AND location_id = $2 FOR UPDATE",
+		productID, locationID).Scan(&available)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if available < quantity {
+		return fmt.Errorf("insufficient stock for product id %d at location %d: requested %d, available %d",
+			productID, locationID, quantity, available)
+	}
+
+	_, err = tx.Exec("UPDATE product_stocks SET quantity = quantity - $1 WHERE product_id = $2 AND location_id = $3",
+		quantity, productID, locationID)
+	return err
+}
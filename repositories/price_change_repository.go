@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir/models"
+	"time"
+)
+
+type PriceChangeRepository struct {
+	db *sql.DB
+}
+
+func NewPriceChangeRepository(db *sql.DB) *PriceChangeRepository {
+	return &PriceChangeRepository{db: db}
+}
+
+const priceChangeColumns = `c.id, c.product_id, p.name, c.new_price, c.effective_at, c.status, c.created_at, c.applied_at`
+
+func scanPriceChange(row rowScanner) (*models.ScheduledPriceChange, error) {
+	var c models.ScheduledPriceChange
+	var appliedAt sql.NullTime
+	err := row.Scan(&c.ID, &c.ProductID, &c.ProductName, &c.NewPrice, &c.EffectiveAt, &c.Status, &c.CreatedAt, &appliedAt)
+	if err != nil {
+		return nil, err
+	}
+	if appliedAt.Valid {
+		c.AppliedAt = &appliedAt.Time
+	}
+	return &c, nil
+}
+
+// GetAll - list perubahan harga terjadwal, bisa difilter status dan produk (productID 0 = semua)
+func (repo *PriceChangeRepository) GetAll(status string, productID int) ([]models.ScheduledPriceChange, error) {
+	query := `SELECT ` + priceChangeColumns + `
+	          FROM scheduled_price_changes c
+	          JOIN products p ON c.product_id = p.id
+	          WHERE ($1 = '' OR c.status = $1) AND ($2::int = 0 OR c.product_id = $2::int)
+	          ORDER BY c.effective_at, c.id`
+
+	rows, err := repo.db.Query(query, status, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]models.ScheduledPriceChange, 0)
+	for rows.Next() {
+		c, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, *c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (repo *PriceChangeRepository) GetByID(id int) (*models.ScheduledPriceChange, error) {
+	query := `SELECT ` + priceChangeColumns + `
+	          FROM scheduled_price_changes c
+	          JOIN products p ON c.product_id = p.id
+	          WHERE c.id = $1`
+
+	c, err := scanPriceChange(repo.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("perubahan harga tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Create - jadwalkan perubahan harga; effective_at harus di masa depan
+func (repo *PriceChangeRepository) Create(change *models.ScheduledPriceChange) error {
+	if change.ProductID <= 0 {
+		return fmt.Errorf("invalid product id: %d", change.ProductID)
+	}
+	if change.NewPrice < 0 {
+		return fmt.Errorf("invalid price for product %d: %d", change.ProductID, change.NewPrice)
+	}
+	if !change.EffectiveAt.After(time.Now()) {
+		return errors.New("effective_at cannot be in the past")
+	}
+
+	change.Status = models.PriceChangePending
+	query := `INSERT INTO scheduled_price_changes (product_id, new_price, effective_at, status)
+	          VALUES ($1, $2, $3, $4) RETURNING id, created_at`
+	err := repo.db.QueryRow(query, change.ProductID, change.NewPrice, change.EffectiveAt, change.Status).
+		Scan(&change.ID, &change.CreatedAt)
+	return err
+}
+
+// Cancel - batalkan perubahan harga yang belum diterapkan
+func (repo *PriceChangeRepository) Cancel(id int) error {
+	result, err := repo.db.Exec("UPDATE scheduled_price_changes SET status = $1 WHERE id = $2 AND status = $3",
+		models.PriceChangeCancelled, id, models.PriceChangePending)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		if _, err := repo.GetByID(id); err != nil {
+			return err
+		}
+		return fmt.Errorf("price change %d cannot be cancelled because it is no longer pending", id)
+	}
+
+	return nil
+}
+
+// ApplyDue - terapkan semua perubahan harga yang sudah jatuh tempo, urut effective_at.
+// Mengembalikan jumlah perubahan yang diterapkan.
+func (repo *PriceChangeRepository) ApplyDue(now time.Time) (int, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// SKIP LOCKED supaya beberapa instance API tidak menerapkan perubahan yang sama
+	rows, err := tx.Query(`SELECT id, product_id, new_price
+	                       FROM scheduled_price_changes
+	                       WHERE status = $1 AND effective_at <= $2
+	                       ORDER BY effective_at, id
+	                       FOR UPDATE SKIP LOCKED`, models.PriceChangePending, now)
+	if err != nil {
+		return 0, err
+	}
+
+	type dueChange struct{ id, productID, newPrice int }
+	due := make([]dueChange, 0)
+	for rows.Next() {
+		var c dueChange
+		if err := rows.Scan(&c.id, &c.productID, &c.newPrice); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, c := range due {
+		var oldPrice int
+		err := tx.QueryRow("SELECT price FROM products WHERE id = $1 FOR UPDATE", c.productID).Scan(&oldPrice)
+		if err != nil {
+			return 0, err
+		}
+
+		if oldPrice != c.newPrice {
+			_, err = tx.Exec("UPDATE products SET price = $1 WHERE id = $2", c.newPrice, c.productID)
+			if err != nil {
+				return 0, err
+			}
+
+			changeID := c.id
+			if err := recordPriceChange(tx, c.productID, &oldPrice, c.newPrice, models.PriceSourceScheduled, &changeID); err != nil {
+				return 0, err
+			}
+		}
+
+		_, err = tx.Exec("UPDATE scheduled_price_changes SET status = $1, applied_at = $2 WHERE id = $3",
+			models.PriceChangeApplied, now, c.id)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(due), nil
+}
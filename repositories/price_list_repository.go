@@ -0,0 +1,240 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir/models"
+)
+
+// queryRower - *sql.DB dan *sql.Tx, supaya resolusi harga bisa dipakai di dalam maupun di luar transaksi
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type PriceListRepository struct {
+	db *sql.DB
+}
+
+func NewPriceListRepository(db *sql.DB) *PriceListRepository {
+	return &PriceListRepository{db: db}
+}
+
+func (repo *PriceListRepository) GetAll() ([]models.PriceList, error) {
+	query := "SELECT id, name, outlet_id, customer_tier, active FROM price_lists ORDER BY id"
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	priceLists := make([]models.PriceList, 0)
+	for rows.Next() {
+		var pl models.PriceList
+		var outletID sql.NullInt64
+		var tier sql.NullString
+		err := rows.Scan(&pl.ID, &pl.Name, &outletID, &tier, &pl.Active)
+		if err != nil {
+			return nil, err
+		}
+		pl.OutletID = nullIntPtr(outletID)
+		pl.CustomerTier = tier.String
+		priceLists = append(priceLists, pl)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return priceLists, nil
+}
+
+// GetByID - ambil price list beserta item-nya
+func (repo *PriceListRepository) GetByID(id int) (*models.PriceList, error) {
+	query := "SELECT id, name, outlet_id, customer_tier, active FROM price_lists WHERE id = $1"
+
+	var pl models.PriceList
+	var outletID sql.NullInt64
+	var tier sql.NullString
+	err := repo.db.QueryRow(query, id).Scan(&pl.ID, &pl.Name, &outletID, &tier, &pl.Active)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("price list tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+	pl.OutletID = nullIntPtr(outletID)
+	pl.CustomerTier = tier.String
+
+	rows, err := repo.db.Query(`SELECT i.id, i.price_list_id, i.product_id, p.name, i.min_quantity, i.price
+	                            FROM price_list_items i
+	                            JOIN products p ON i.product_id = p.id
+	                            WHERE i.price_list_id = $1
+	                            ORDER BY i.product_id, i.min_quantity`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pl.Items = make([]models.PriceListItem, 0)
+	for rows.Next() {
+		var item models.PriceListItem
+		err := rows.Scan(&item.ID, &item.PriceListID, &item.ProductID, &item.ProductName, &item.MinQuantity, &item.Price)
+		if err != nil {
+			return nil, err
+		}
+		pl.Items = append(pl.Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &pl, nil
+}
+
+func (repo *PriceListRepository) Create(pl *models.PriceList) error {
+	if err := validatePriceListItems(pl.Items); err != nil {
+		return err
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO price_lists (name, outlet_id, customer_tier, active)
+	          VALUES ($1, $2, NULLIF($3, ''), COALESCE($4::boolean, TRUE)) RETURNING id, active`
+	err = tx.QueryRow(query, pl.Name, pl.OutletID, pl.CustomerTier, pl.Active).Scan(&pl.ID, &pl.Active)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPriceListItems(tx, pl); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update - ubah price list; item lama diganti seluruhnya dengan item di request
+func (repo *PriceListRepository) Update(pl *models.PriceList) error {
+	if err := validatePriceListItems(pl.Items); err != nil {
+		return err
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE price_lists SET name = $1, outlet_id = $2, customer_tier = NULLIF($3, ''),
+	                 active = COALESCE($4::boolean, active)
+	          WHERE id = $5 RETURNING active`
+	err = tx.QueryRow(query, pl.Name, pl.OutletID, pl.CustomerTier, pl.Active, pl.ID).Scan(&pl.Active)
+	if err == sql.ErrNoRows {
+		return errors.New("price list tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM price_list_items WHERE price_list_id = $1", pl.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPriceListItems(tx, pl); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (repo *PriceListRepository) Delete(id int) error {
+	query := "DELETE FROM price_lists WHERE id = $1"
+	result, err := repo.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("price list tidak ditemukan")
+	}
+
+	return nil
+}
+
+func validatePriceListItems(items []models.PriceListItem) error {
+	for _, item := range items {
+		if item.ProductID <= 0 {
+			return fmt.Errorf("invalid product id: %d", item.ProductID)
+		}
+		if item.MinQuantity < 0 {
+			return fmt.Errorf("invalid min quantity for product %d: %d", item.ProductID, item.MinQuantity)
+		}
+		if item.Price < 0 {
+			return fmt.Errorf("invalid price for product %d: %d", item.ProductID, item.Price)
+		}
+	}
+	return nil
+}
+
+func insertPriceListItems(tx *sql.Tx, pl *models.PriceList) error {
+	query := `INSERT INTO price_list_items (price_list_id, product_id, min_quantity, price)
+	          VALUES ($1, $2, $3, $4) RETURNING id`
+
+	for i := range pl.Items {
+		item := &pl.Items[i]
+		item.PriceListID = pl.ID
+		if item.MinQuantity == 0 {
+			item.MinQuantity = 1
+		}
+		err := tx.QueryRow(query, pl.ID, item.ProductID, item.MinQuantity, item.Price).Scan(&item.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolvePrice - tentukan harga jual produk untuk konteks tertentu.
+//
+// Urutan resolusi: price list aktif untuk outlet + tier pelanggan, lalu tier saja,
+// lalu outlet saja, lalu price list umum (tanpa outlet/tier), terakhir products.price.
+// Di tiap tingkat dipilih item dengan min_quantity terbesar yang masih terpenuhi.
+// Mengembalikan priceListID 0 kalau yang dipakai adalah harga dasar.
+func resolvePrice(q queryRower, productID, basePrice int, pc models.PriceContext) (price, priceListID int, err error) {
+	quantity := pc.Quantity
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	query := `SELECT i.price, pl.id
+	          FROM price_list_items i
+	          JOIN price_lists pl ON i.price_list_id = pl.id
+	          WHERE pl.active AND i.product_id = $1 AND i.min_quantity <= $2
+	            AND (pl.outlet_id IS NULL OR pl.outlet_id = $3)
+	            AND (pl.customer_tier IS NULL OR pl.customer_tier = $4)
+	          ORDER BY (pl.customer_tier IS NOT NULL) DESC, (pl.outlet_id IS NOT NULL) DESC,
+	                   i.min_quantity DESC, i.price ASC, pl.id
+	          LIMIT 1`
+
+	err = q.QueryRow(query, productID, quantity, pc.OutletID, pc.CustomerTier).Scan(&price, &priceListID)
+	if err == sql.ErrNoRows {
+		return basePrice, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return price, priceListID, nil
+}
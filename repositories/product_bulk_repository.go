@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"kasir/models"
+	"math"
+	"strings"
+)
+
+// BulkUpdate - ubah harga atau stok banyak produk sekaligus dalam satu transaksi.
+// Produk yang diarsipkan tidak ikut. Perubahan stok ditolak untuk produk track_expiry
+// atau yang punya stok per lokasi. Kalau dryRun, perubahan dihitung lalu di-rollback
+// sehingga hasilnya bisa dipakai sebagai preview.
+func (repo *ProductRepository) BulkUpdate(req models.BulkUpdateRequest, dryRun bool) (*models.BulkUpdateResult, error) {
+	if err := validateBulkUpdate(req); err != nil {
+		return nil, err
+	}
+
+	where := []string{"archived_at IS NULL"}
+	args := []interface{}{}
+	if req.Filter.CategoryID > 0 {
+		args = append(args, req.Filter.CategoryID)
+		where = append(where, "category_id IN "+categorySubtree(fmt.Sprintf("$%d", len(args))))
+	}
+	if req.Filter.SupplierID > 0 {
+		args = append(args, req.Filter.SupplierID)
+		where = append(where, fmt.Sprintf("supplier_id = $%d", len(args)))
+	}
+	if len(req.Filter.IDs) > 0 {
+		placeholders := make([]string, len(req.Filter.IDs))
+		for i, id := range req.Filter.IDs {
+			args = append(args, id)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		where = append(where, "id IN ("+strings.Join(placeholders, ", ")+")")
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// req.Field sudah divalidasi, aman dipakai sebagai nama kolom
+	rows, err := tx.Query("SELECT id, name, "+req.Field+" FROM products WHERE "+strings.Join(where, " AND ")+" ORDER BY id FOR UPDATE", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.BulkUpdateResult{
+		DryRun: dryRun,
+		Field:  req.Field,
+		Items:  make([]models.BulkUpdateItem, 0),
+	}
+	for rows.Next() {
+		var item models.BulkUpdateItem
+		if err := rows.Scan(&item.ProductID, &item.Name, &item.OldValue); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		item.NewValue = applyBulkOperation(item.OldValue, req)
+		if item.NewValue < 0 {
+			rows.Close()
+			return nil, fmt.Errorf("invalid %s for product %d: result %d is below zero", req.Field, item.ProductID, item.NewValue)
+		}
+		result.Items = append(result.Items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result.Matched = len(result.Items)
+	for _, item := range result.Items {
+		if item.NewValue == item.OldValue {
+			continue
+		}
+		result.Changed++
+
+		// Stok batch/lokasi tidak ikut berubah, jadi produk seperti itu ditolak supaya totalnya tetap cocok
+		if req.Field == models.BulkFieldStock {
+			reason, err := stockOverwriteBlocked(tx, item.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				return nil, errors.New(reason)
+			}
+		}
+
+		_, err := tx.Exec("UPDATE products SET "+req.Field+" = $1 WHERE id = $2", item.NewValue, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.Field == models.BulkFieldPrice {
+			oldPrice := item.OldValue
+			if err := recordPriceChange(tx, item.ProductID, &oldPrice, item.NewValue, models.PriceSourceBulk, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+
+	return result, nil
+}
+
+func validateBulkUpdate(req models.BulkUpdateRequest) error {
+	if req.Filter.CategoryID <= 0 && req.Filter.SupplierID <= 0 && len(req.Filter.IDs) == 0 {
+		return errors.New("filter is required: category_id, supplier_id or ids")
+	}
+	for _, id := range req.Filter.IDs {
+		if id <= 0 {
+			return fmt.Errorf("invalid product id: %d", id)
+		}
+	}
+
+	if req.Field != models.BulkFieldPrice && req.Field != models.BulkFieldStock {
+		return fmt.Errorf("invalid field: %q", req.Field)
+	}
+
+	switch req.Operation {
+	case models.BulkSet:
+		if req.Value < 0 || req.Value != math.Trunc(req.Value) {
+			return fmt.Errorf("invalid value for set: %v", req.Value)
+		}
+	case models.BulkIncreaseAmount:
+		if req.Value != math.Trunc(req.Value) {
+			return fmt.Errorf("invalid value for increase_amount: %v", req.Value)
+		}
+	case models.BulkIncreasePercent:
+		if req.Value < -100 {
+			return fmt.Errorf("invalid value for increase_percent: %v", req.Value)
+		}
+	default:
+		return fmt.Errorf("invalid operation: %q", req.Operation)
+	}
+
+	switch req.Rounding {
+	case 0, 100, 500:
+	default:
+		return fmt.Errorf("invalid rounding: %d (allowed: 0, 100, 500)", req.Rounding)
+	}
+
+	return nil
+}
+
+// applyBulkOperation - hitung nilai baru lalu bulatkan ke kelipatan Rounding terdekat
+func applyBulkOperation(old int, req models.BulkUpdateRequest) int {
+	var value float64
+	switch req.Operation {
+	case models.BulkSet:
+		value = req.Value
+	case models.BulkIncreaseAmount:
+		value = float64(old) + req.Value
+	case models.BulkIncreasePercent:
+		value = float64(old) * (1 + req.Value/100)
+	}
+
+	if req.Rounding > 0 {
+		step := float64(req.Rounding)
+		return int(math.Round(value/step) * step)
+	}
+	return int(math.Round(value))
+}
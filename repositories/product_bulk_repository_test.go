@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"kasir/models"
+	"strings"
+	"testing"
+)
+
+func TestValidateBulkUpdate(t *testing.T) {
+	byCategory := models.BulkUpdateFilter{CategoryID: 3}
+
+	tests := []struct {
+		name string
+		req  models.BulkUpdateRequest
+		want string // potongan pesan error, "" = valid
+	}{
+		{"set price", models.BulkUpdateRequest{Filter: byCategory, Field: models.BulkFieldPrice, Operation: models.BulkSet, Value: 15000}, ""},
+		{"filter by ids", models.BulkUpdateRequest{Filter: models.BulkUpdateFilter{IDs: []int{1, 2}}, Field: models.BulkFieldStock, Operation: models.BulkIncreaseAmount, Value: -5}, ""},
+		{"filter by supplier", models.BulkUpdateRequest{Filter: models.BulkUpdateFilter{SupplierID: 7}, Field: models.BulkFieldPrice, Operation: models.BulkIncreasePercent, Value: -100, Rounding: 500}, ""},
+		{"no filter", models.BulkUpdateRequest{Field: models.BulkFieldPrice, Operation: models.BulkSet, Value: 1}, "filter is required"},
+		{"non-positive id", models.BulkUpdateRequest{Filter: models.BulkUpdateFilter{IDs: []int{4, 0}}, Field: models.BulkFieldPrice, Operation: models.BulkSet, Value: 1}, "invalid product id: 0"},
+		{"unknown field", models.BulkUpdateRequest{Filter: byCategory, Field: "cost_price", Operation: models.BulkSet, Value: 1}, "invalid field"},
+		{"negative set", models.BulkUpdateRequest{Filter: byCategory, Field: models.BulkFieldPrice, Operation: models.BulkSet, Value: -1}, "invalid value for set"},
+		{"fractional set", models.BulkUpdateRequest{Filter: byCategory, Field: models.BulkFieldStock, Operation: models.BulkSet, Value: 2.5}, "invalid value for set"},
+		{"fractional amount", models.BulkUpdateRequest{Filter: byCategory, Field: models.BulkFieldPrice, Operation: models.BulkIncreaseAmount, Value: 0.5}, "invalid value for increase_amount"},
+		{"percent below -100", models.BulkUpdateRequest{Filter: byCategory, Field: models.BulkFieldPrice, Operation: models.BulkIncreasePercent, Value: -101}, "invalid value for increase_percent"},
+		{"unknown operation", models.BulkUpdateRequest{Filter: byCategory, Field: models.BulkFieldPrice, Operation: "multiply", Value: 2}, "invalid operation"},
+		{"unsupported rounding", models.BulkUpdateRequest{Filter: byCategory, Field: models.BulkFieldPrice, Operation: models.BulkSet, Value: 1, Rounding: 250}, "invalid rounding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBulkUpdate(tt.req)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyBulkOperation(t *testing.T) {
+	tests := []struct {
+		name      string
+		old       int
+		operation string
+		value     float64
+		rounding  int
+		want      int
+	}{
+		{"set", 12000, models.BulkSet, 15000, 0, 15000},
+		{"set rounded to 500", 12000, models.BulkSet, 15240, 500, 15000},
+		{"increase amount", 12000, models.BulkIncreaseAmount, 750, 0, 12750},
+		{"decrease amount", 10, models.BulkIncreaseAmount, -4, 0, 6},
+		{"increase percent", 12000, models.BulkIncreasePercent, 10, 0, 13200},
+		{"increase percent rounded to 100", 12345, models.BulkIncreasePercent, 10, 100, 13600},
+		{"increase percent rounded to 500", 12345, models.BulkIncreasePercent, 10, 500, 13500},
+		{"decrease percent", 9999, models.BulkIncreasePercent, -15, 0, 8499},
+		{"half rounds away from zero", 1, models.BulkIncreasePercent, 50, 0, 2},
+		{"minus 100 percent", 8000, models.BulkIncreasePercent, -100, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := models.BulkUpdateRequest{Operation: tt.operation, Value: tt.value, Rounding: tt.rounding}
+			if got := applyBulkOperation(tt.old, req); got != tt.want {
+				t.Errorf("applyBulkOperation(%d) = %d, want %d", tt.old, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir/models"
+	"strings"
+)
+
+// Import - upsert produk by SKU dalam satu transaksi. Kategori dicari by nama (case-insensitive),
+// kategori yang belum ada dibuat baru. Kalau dryRun atau ada error di salah satu baris,
+// transaksi di-rollback sehingga report tetap akurat tanpa ada data yang tersimpan.
+func (repo *ProductRepository) Import(rows []models.ProductImportRow, dryRun bool) (*models.ProductImportReport, error) {
+	report := &models.ProductImportReport{
+		DryRun:        dryRun,
+		TotalRows:     len(rows),
+		NewCategories: make([]string, 0),
+		Errors:        make([]models.ProductImportError, 0),
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	categories, err := activeCategoryIDs(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		var categoryID *int
+		if row.Category != "" {
+			key := strings.ToLower(row.Category)
+			id, ok := categories[key]
+			if !ok {
+				err := tx.QueryRow("INSERT INTO categories (name) VALUES ($1) RETURNING id", row.Category).Scan(&id)
+				if err != nil {
+					return nil, err
+				}
+				categories[key] = id
+				report.NewCategories = append(report.NewCategories, row.Category)
+			}
+			categoryID = &id
+		}
+
+		var id, oldPrice, oldStock int
+		err := tx.QueryRow("SELECT id, price, stock FROM products WHERE sku = $1 FOR UPDATE", row.SKU).Scan(&id, &oldPrice, &oldStock)
+		if err == sql.ErrNoRows {
+			if row.Name == "" {
+				report.Errors = append(report.Errors, models.ProductImportError{Row: row.Row, SKU: row.SKU, Field: "name", Message: "name is required for new products"})
+				continue
+			}
+			if row.Price == nil {
+				report.Errors = append(report.Errors, models.ProductImportError{Row: row.Row, SKU: row.SKU, Field: "price", Message: "price is required for new products"})
+				continue
+			}
+
+			if row.TrackExpiry != nil && *row.TrackExpiry && intOrZero(row.Stock) != 0 {
+				report.Errors = append(report.Errors, models.ProductImportError{Row: row.Row, SKU: row.SKU, Field: "stock",
+					Message: "stock cannot be imported for products that track expiry, receive it through a purchase order"})
+				continue
+			}
+
+			product := models.Product{
+				SKU:         row.SKU,
+				Name:        row.Name,
+				Price:       *row.Price,
+				CostPrice:   intOrZero(row.CostPrice),
+				Stock:       intOrZero(row.Stock),
+				MinStock:    intOrZero(row.MinStock),
+				ReorderQty:  intOrZero(row.ReorderQty),
+				TrackExpiry: row.TrackExpiry != nil && *row.TrackExpiry,
+			}
+			err := tx.QueryRow(`INSERT INTO products (sku, name, price, cost_price, stock, track_expiry, min_stock, reorder_qty, category_id)
+			                    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+				product.SKU, product.Name, product.Price, product.CostPrice, product.Stock, product.TrackExpiry,
+				product.MinStock, product.ReorderQty, categoryID).Scan(&product.ID)
+			if err != nil {
+				return nil, err
+			}
+			if err := recordPriceChange(tx, product.ID, nil, product.Price, models.PriceSourceImport, nil); err != nil {
+				return nil, err
+			}
+
+			report.Created++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Stok produk yang punya batch expiry atau stok per lokasi tidak boleh ditimpa dari import,
+		// karena batch dan product_stocks tidak ikut berubah
+		if row.Stock != nil && *row.Stock != oldStock {
+			reason, err := stockOverwriteBlocked(tx, id)
+			if err != nil {
+				return nil, err
+			}
+			if reason != "" {
+				report.Errors = append(report.Errors, models.ProductImportError{Row: row.Row, SKU: row.SKU, Field: "stock", Message: reason})
+				continue
+			}
+		}
+
+		// Sel kosong = nilai lama dipertahankan; kategori kosong juga tidak mengubah kategori
+		_, err = tx.Exec(`UPDATE products SET name = COALESCE(NULLIF($1, ''), name),
+		                         price = COALESCE($2, price), cost_price = COALESCE($3, cost_price),
+		                         stock = COALESCE($4, stock), min_stock = COALESCE($5, min_stock),
+		                         reorder_qty = COALESCE($6, reorder_qty), track_expiry = COALESCE($7, track_expiry),
+		                         category_id = COALESCE($8, category_id)
+		                  WHERE id = $9`,
+			row.Name, row.Price, row.CostPrice, row.Stock, row.MinStock, row.ReorderQty, row.TrackExpiry, categoryID, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if row.Price != nil && *row.Price != oldPrice {
+			if err := recordPriceChange(tx, id, &oldPrice, *row.Price, models.PriceSourceImport, nil); err != nil {
+				return nil, err
+			}
+		}
+
+		report.Updated++
+	}
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	report.Applied = true
+
+	return report, nil
+}
+
+// GetCatalogue - seluruh produk aktif beserta kategori dan stok, untuk export
+func (repo *ProductRepository) GetCatalogue() ([]models.Product, error) {
+	query := `SELECT ` + productColumns("p.stock") + `
+	          FROM products p
+	          LEFT JOIN categories c ON p.category_id = c.id
+	          WHERE p.archived_at IS NULL
+	          ORDER BY p.sku NULLS LAST, p.name, p.id`
+
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, *p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// activeCategoryIDs - map nama kategori (lowercase) -> id, kategori yang diarsipkan tidak ikut
+func activeCategoryIDs(tx *sql.Tx) (map[string]int, error) {
+	rows, err := tx.Query("SELECT id, name FROM categories WHERE archived_at IS NULL ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(name)
+		if _, ok := categories[key]; !ok {
+			categories[key] = id
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func intOrZero(n *int) int {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
@@ -3,7 +3,9 @@ package repositories
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"kasir/models"
+	"math"
 )
 
 type ProductRepository struct {
@@ -14,19 +16,156 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
-	query := `SELECT p.id, p.name, p.price, p.stock,
-	                 c.id, c.name, c.description
-	          FROM products p
-	          LEFT JOIN categories c ON p.category_id = c.id`
+// rowScanner - *sql.Row dan *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// productColumns - kolom produk + kategori untuk scanProduct; stockColumn bisa diganti stok per outlet
+func productColumns(stockColumn string) string {
+	return `p.id, COALESCE(p.sku, ''), COALESCE(p.barcode, ''), p.name, p.price, p.cost_price, ` + stockColumn + `, p.track_expiry, p.track_serial,
+	        p.min_stock, p.reorder_qty, p.supplier_id, COALESCE(p.image_key, ''), p.updated_at, p.archived_at,
+	        c.id, c.name, c.description`
+}
+
+func scanProduct(row rowScanner) (*models.Product, error) {
+	var p models.Product
+	var supplierID sql.NullInt64
+	var archivedAt sql.NullTime
+	var categoryID sql.NullInt64
+	var categoryName sql.NullString
+	var categoryDesc sql.NullString
+
+	err := row.Scan(&p.ID, &p.SKU, &p.Barcode, &p.Name, &p.Price, &p.CostPrice, &p.Stock, &p.TrackExpiry, &p.TrackSerial,
+		&p.MinStock, &p.ReorderQty, &supplierID, &p.ImageKey, &p.UpdatedAt, &archivedAt,
+		&categoryID, &categoryName, &categoryDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	p.SupplierID = nullIntPtr(supplierID)
+	if archivedAt.Valid {
+		p.ArchivedAt = &archivedAt.Time
+	}
+	if categoryID.Valid {
+		p.Category = &models.Category{
+			ID:          int(categoryID.Int64),
+			Name:        categoryName.String,
+			Description: categoryDesc.String,
+		}
+	}
+
+	return &p, nil
+}
+
+// productSortColumns - kolom sort yang boleh dipakai di listing produk
+var productSortColumns = map[string]string{
+	"name":       "p.name",
+	"price":      "p.price",
+	"stock":      "stock",
+	"updated_at": "p.updated_at",
+}
+
+const (
+	defaultProductPageSize = 50
+	maxProductPageSize     = 200
+)
 
+// GetAll - list produk dengan filter, sorting dan pagination. Filter kategori ikut mencakup subkategori.
+// Kalau OutletID diisi, stok (termasuk filter status stok dan sort stok) memakai stok di outlet tersebut.
+func (repo *ProductRepository) GetAll(filter models.ProductFilter) (*models.ProductPage, error) {
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize <= 0 {
+		filter.PageSize = defaultProductPageSize
+	}
+	if filter.PageSize > maxProductPageSize {
+		filter.PageSize = maxProductPageSize
+	}
+	if filter.Sort == "" {
+		filter.Sort = "name"
+	}
+	sortColumn, ok := productSortColumns[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("invalid sort field: %s", filter.Sort)
+	}
+	order := "ASC"
+	switch filter.Order {
+	case "", "asc":
+	case "desc":
+		order = "DESC"
+	default:
+		return nil, fmt.Errorf("invalid sort order: %s", filter.Order)
+	}
+
+	stockColumn := "p.stock"
+	stockJoin := ""
 	args := []interface{}{}
-	if name != "" {
-		query += " WHERE p.name ILIKE $1"
-		args = append(args, "%"+name+"%")
+	if filter.OutletID > 0 {
+		stockColumn = "COALESCE(ps.quantity, 0)"
+		stockJoin = " LEFT JOIN product_stocks ps ON ps.product_id = p.id AND ps.location_id = $1"
+		args = append(args, filter.OutletID)
+	}
+
+	where := " WHERE TRUE"
+	switch filter.Archived {
+	case "", models.ArchivedExclude:
+		where += " AND p.archived_at IS NULL"
+	case models.ArchivedOnly:
+		where += " AND p.archived_at IS NOT NULL"
+	case models.ArchivedInclude:
+	default:
+		return nil, fmt.Errorf("invalid archived filter: %s", filter.Archived)
+	}
+	if filter.Name != "" {
+		args = append(args, "%"+filter.Name+"%")
+		where += fmt.Sprintf(" AND p.name ILIKE $%d", len(args))
+	}
+	if filter.CategoryID > 0 {
+		args = append(args, filter.CategoryID)
+		where += " AND p.category_id IN " + categorySubtree(fmt.Sprintf("$%d", len(args)))
+	}
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		where += fmt.Sprintf(" AND p.price >= $%d", len(args))
+	}
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		where += fmt.Sprintf(" AND p.price <= $%d", len(args))
+	}
+	switch filter.StockStatus {
+	case "":
+	case models.StockStatusInStock:
+		where += " AND " + stockColumn + " > 0"
+	case models.StockStatusLowStock:
+		where += " AND " + stockColumn + " > 0 AND " + stockColumn + " <= p.min_stock"
+	case models.StockStatusOutOfStock:
+		where += " AND " + stockColumn + " <= 0"
+	default:
+		return nil, fmt.Errorf("invalid stock status: %s", filter.StockStatus)
+	}
+
+	from := `
+	          FROM products p
+	          LEFT JOIN categories c ON p.category_id = c.id` + stockJoin + where
+
+	page := &models.ProductPage{
+		Data:     make([]models.Product, 0),
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
 	}
 
-	query += " ORDER BY p.id"
+	err := repo.db.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+	page.TotalPages = (page.Total + filter.PageSize - 1) / filter.PageSize
+
+	// p.id sebagai tie-breaker supaya urutan antar halaman stabil
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+	query := `SELECT ` + productColumns(stockColumn+" AS stock") + from +
+		fmt.Sprintf(" ORDER BY %s %s, p.id %s LIMIT $%d OFFSET $%d", sortColumn, order, order, len(args)-1, len(args))
 
 	rows, err := repo.db.Query(query, args...)
 	if err != nil {
@@ -34,60 +173,58 @@ func (repo *ProductRepository) GetAll(name string) ([]models.Product, error) {
 	}
 	defer rows.Close()
 
-	products := make([]models.Product, 0)
 	for rows.Next() {
-		var p models.Product
-		var categoryID sql.NullInt64
-		var categoryName sql.NullString
-		var categoryDesc sql.NullString
-
-		err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Stock,
-			&categoryID, &categoryName, &categoryDesc)
+		p, err := scanProduct(rows)
 		if err != nil {
 			return nil, err
 		}
+		page.Data = append(page.Data, *p)
+	}
 
-		if categoryID.Valid {
-			p.Category = &models.Category{
-				ID:          int(categoryID.Int64),
-				Name:        categoryName.String,
-				Description: categoryDesc.String,
-			}
-		}
-
-		products = append(products, p)
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return products, nil
+	return page, nil
 }
 
 func (repo *ProductRepository) Create(product *models.Product) error {
-	query := "INSERT INTO products (name, price, stock, category_id) VALUES ($1, $2, $3, $4) RETURNING id"
-	err := repo.db.QueryRow(query, product.Name, product.Price, product.Stock,
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO products (name, price, cost_price, stock, track_expiry, min_stock, reorder_qty, supplier_id, category_id, sku, barcode, track_serial)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''), $12) RETURNING id`
+	err = tx.QueryRow(query, product.Name, product.Price, product.CostPrice, product.Stock, product.TrackExpiry,
+		product.MinStock, product.ReorderQty, product.SupplierID,
 		func() *int {
 			if product.Category != nil {
 				return &product.Category.ID
 			}
 			return nil
-		}()).Scan(&product.ID)
-	return err
+		}(), product.SKU, product.Barcode, product.TrackSerial).Scan(&product.ID)
+	if err != nil {
+		return err
+	}
+
+	// Harga awal dicatat sebagai titik pertama riwayat harga
+	if err := recordPriceChange(tx, product.ID, nil, product.Price, models.PriceSourceManual, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-// GetByID - ambil produk by ID
-func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
-	query := `SELECT p.id, p.name, p.price, p.stock,
-	                 c.id, c.name, c.description
+// GetByID - ambil produk by ID, harga sudah diresolusi lewat price list sesuai konteks
+func (repo *ProductRepository) GetByID(id int, pc models.PriceContext) (*models.Product, error) {
+	query := `SELECT ` + productColumns("p.stock") + `
 	          FROM products p
 	          LEFT JOIN categories c ON p.category_id = c.id
 	          WHERE p.id = $1`
 
-	var p models.Product
-	var categoryID sql.NullInt64
-	var categoryName sql.NullString
-	var categoryDesc sql.NullString
-
-	err := repo.db.QueryRow(query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Stock,
-		&categoryID, &categoryName, &categoryDesc)
+	p, err := scanProduct(repo.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, errors.New("produk tidak ditemukan")
 	}
@@ -95,18 +232,29 @@ func (repo *ProductRepository) GetByID(id int) (*models.Product, error) {
 		return nil, err
 	}
 
-	if categoryID.Valid {
-		p.Category = &models.Category{
-			ID:          int(categoryID.Int64),
-			Name:        categoryName.String,
-			Description: categoryDesc.String,
-		}
+	components, err := repo.GetComponents(p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) > 0 {
+		p.Components = components
 	}
 
-	return &p, nil
+	price, priceListID, err := resolvePrice(repo.db, p.ID, p.Price, pc)
+	if err != nil {
+		return nil, err
+	}
+	if priceListID > 0 {
+		p.BasePrice = p.Price
+		p.Price = price
+		p.PriceListID = &priceListID
+	}
+
+	return p, nil
 }
 
-func (repo *ProductRepository) Update(product *models.Product) error {
+// Update - ubah produk; field opsional yang nil di ProductUpdate tidak diubah
+func (repo *ProductRepository) Update(id int, product models.ProductUpdate) error {
 	categoryID := func() *int {
 		if product.Category != nil {
 			return &product.Category.ID
@@ -114,8 +262,76 @@ func (repo *ProductRepository) Update(product *models.Product) error {
 		return nil
 	}()
 
-	query := "UPDATE products SET name = $1, price = $2, stock = $3, category_id = $4 WHERE id = $5"
-	result, err := repo.db.Exec(query, product.Name, product.Price, product.Stock, categoryID, product.ID)
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldPrice, oldStock int
+	err = tx.QueryRow("SELECT price, stock FROM products WHERE id = $1 FOR UPDATE", id).Scan(&oldPrice, &oldStock)
+	if err == sql.ErrNoRows {
+		return errors.New("produk tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Sama dengan import dan bulk update: stok produk yang punya batch expiry atau stok per lokasi
+	// tidak boleh ditimpa, karena batch dan product_stocks tidak ikut berubah
+	if product.Stock != nil && *product.Stock != oldStock {
+		reason, err := stockOverwriteBlocked(tx, id)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return errors.New(reason)
+		}
+	}
+
+	query := `UPDATE products SET name = $1, price = $2, stock = COALESCE($3::int, stock), category_id = $4,
+	                 track_expiry = COALESCE($5::boolean, track_expiry),
+	                 min_stock = COALESCE($6::int, min_stock),
+	                 reorder_qty = COALESCE($7::int, reorder_qty),
+	                 supplier_id = CASE WHEN $8::int IS NULL THEN supplier_id ELSE NULLIF($8::int, 0) END,
+	                 sku = CASE WHEN $9::text IS NULL THEN sku ELSE NULLIF($9::text, '') END,
+	                 barcode = CASE WHEN $10::text IS NULL THEN barcode ELSE NULLIF($10::text, '') END,
+	                 track_serial = COALESCE($11::boolean, track_serial)
+	          WHERE id = $12`
+	_, err = tx.Exec(query, product.Name, product.Price, product.Stock, categoryID, product.TrackExpiry,
+		product.MinStock, product.ReorderQty, product.SupplierID, product.SKU, product.Barcode, product.TrackSerial, id)
+	if err != nil {
+		return err
+	}
+
+	if oldPrice != product.Price {
+		if err := recordPriceChange(tx, id, &oldPrice, product.Price, models.PriceSourceManual, nil); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Archive - arsipkan produk (soft delete) supaya riwayat transaksi tetap utuh
+func (repo *ProductRepository) Archive(id int) error {
+	return setArchived(repo.db, "products", id, true, "produk tidak ditemukan")
+}
+
+// Restore - kembalikan produk yang diarsipkan
+func (repo *ProductRepository) Restore(id int) error {
+	return setArchived(repo.db, "products", id, false, "produk tidak ditemukan")
+}
+
+// setArchived - set/hapus archived_at di tabel products atau categories.
+// Mengarsipkan ulang baris yang sudah diarsipkan tidak mengubah archived_at.
+func setArchived(db *sql.DB, table string, id int, archived bool, notFound string) error {
+	value := "NULL"
+	if archived {
+		value = "COALESCE(archived_at, NOW())"
+	}
+
+	result, err := db.Exec("UPDATE "+table+" SET archived_at = "+value+" WHERE id = $1", id)
 	if err != nil {
 		return err
 	}
@@ -126,26 +342,369 @@ func (repo *ProductRepository) Update(product *models.Product) error {
 	}
 
 	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+		return errors.New(notFound)
 	}
 
 	return nil
 }
 
-func (repo *ProductRepository) Delete(id int) error {
-	query := "DELETE FROM products WHERE id = $1"
-	result, err := repo.db.Exec(query, id)
+// ReceiveStock - tambah stok produk dari penerimaan barang, dipanggil di dalam transaksi.
+// Harga pokok dihitung ulang dengan metode rata-rata tertimbang (weighted average).
+func (repo *ProductRepository) ReceiveStock(tx *sql.Tx, id, quantity, unitCost int) error {
+	if quantity <= 0 {
+		return fmt.Errorf("quantity must be greater than 0 for product id %d", id)
+	}
+
+	// Stok minus tidak ikut dihitung supaya rata-rata tidak terdistorsi
+	query := `UPDATE products
+	          SET cost_price = ROUND((GREATEST(stock, 0)::numeric * cost_price + $1::numeric * $2::numeric)
+	                                 / (GREATEST(stock, 0) + $1::int)),
+	              stock = stock + $1::int
+	          WHERE id = $3`
+
+	result, err := tx.Exec(query, quantity, unitCost, id)
 	if err != nil {
 		return err
 	}
+
 	rows, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 
 	if rows == 0 {
-		return errors.New("produk tidak ditemukan")
+		return fmt.Errorf("product id %d not found", id)
 	}
 
+	return nil
+}
+
+// GetPurchaseHistory - riwayat penerimaan barang untuk satu produk
+func (repo *ProductRepository) GetPurchaseHistory(id int) ([]models.PurchaseHistory, error) {
+	query := `SELECT gr.id, po.id, s.id, s.name, grl.quantity, grl.unit_cost, gr.received_at
+	          FROM goods_receipt_lines grl
+	          JOIN goods_receipts gr ON grl.goods_receipt_id = gr.id
+	          JOIN purchase_orders po ON gr.purchase_order_id = po.id
+	          JOIN suppliers s ON po.supplier_id = s.id
+	          WHERE grl.product_id = $1
+	          ORDER BY gr.received_at DESC, grl.id DESC`
+
+	rows, err := repo.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]models.PurchaseHistory, 0)
+	for rows.Next() {
+		var h models.PurchaseHistory
+		err := rows.Scan(&h.GoodsReceiptID, &h.PurchaseOrderID, &h.SupplierID, &h.SupplierName,
+			&h.Quantity, &h.UnitCost, &h.ReceivedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// GetPriceHistory - riwayat perubahan harga satu produk, terbaru di atas
+func (repo *ProductRepository) GetPriceHistory(id int) ([]models.PriceHistory, error) {
+	query := `SELECT id, product_id, old_price, new_price, source, reference_id, changed_at
+	          FROM product_price_history
+	          WHERE product_id = $1
+	          ORDER BY changed_at DESC, id DESC`
+
+	rows, err := repo.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]models.PriceHistory, 0)
+	for rows.Next() {
+		var h models.PriceHistory
+		var oldPrice, referenceID sql.NullInt64
+		err := rows.Scan(&h.ID, &h.ProductID, &oldPrice, &h.NewPrice, &h.Source, &referenceID, &h.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		h.OldPrice = nullIntPtr(oldPrice)
+		h.ReferenceID = nullIntPtr(referenceID)
+		history = append(history, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// recordPriceChange - catat perubahan harga ke riwayat harga
+func recordPriceChange(tx *sql.Tx, productID int, oldPrice *int, newPrice int, source string, referenceID *int) error {
+	_, err := tx.Exec(`INSERT INTO product_price_history (product_id, old_price, new_price, source, reference_id)
+	                   VALUES ($1, $2, $3, $4, $5)`, productID, oldPrice, newPrice, source, referenceID)
 	return err
 }
+
+// GetComponents - resep produk komposit; kosong kalau produk biasa
+func (repo *ProductRepository) GetComponents(id int) ([]models.ProductComponent, error) {
+	query := `SELECT pc.component_id, p.name, pc.quantity
+	          FROM product_components pc
+	          JOIN products p ON pc.component_id = p.id
+	          WHERE pc.product_id = $1
+	          ORDER BY pc.component_id`
+
+	rows, err := repo.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := make([]models.ProductComponent, 0)
+	for rows.Next() {
+		var c models.ProductComponent
+		err := rows.Scan(&c.ComponentID, &c.ComponentName, &c.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
+
+// SetComponents - ganti resep produk. Resep bertingkat tidak didukung: komponen tidak boleh
+// produk komposit, dan produk yang dipakai sebagai komponen tidak boleh punya resep.
+func (repo *ProductRepository) SetComponents(id int, components []models.ProductComponent) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM products WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("produk tidak ditemukan")
+	}
+
+	if len(components) > 0 {
+		var usedAsComponent bool
+		err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM product_components WHERE component_id = $1)", id).Scan(&usedAsComponent)
+		if err != nil {
+			return err
+		}
+		if usedAsComponent {
+			return fmt.Errorf("product id %d is a component of another product and cannot have components", id)
+		}
+	}
+
+	_, err = tx.Exec("DELETE FROM product_components WHERE product_id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[int]bool)
+	for _, c := range components {
+		if c.ComponentID <= 0 || c.ComponentID == id {
+			return fmt.Errorf("invalid component id: %d", c.ComponentID)
+		}
+		if c.Quantity <= 0 {
+			return fmt.Errorf("invalid quantity for component %d: %d", c.ComponentID, c.Quantity)
+		}
+		if seen[c.ComponentID] {
+			return fmt.Errorf("invalid component id: %d is listed more than once", c.ComponentID)
+		}
+		seen[c.ComponentID] = true
+
+		var componentExists, isComposite, isSerialized bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM products WHERE id = $1),
+		                           EXISTS (SELECT 1 FROM product_components WHERE product_id = $1),
+		                           EXISTS (SELECT 1 FROM products WHERE id = $1 AND track_serial)`, c.ComponentID).
+			Scan(&componentExists, &isComposite, &isSerialized)
+		if err != nil {
+			return err
+		}
+		if !componentExists {
+			return fmt.Errorf("component id %d not found", c.ComponentID)
+		}
+		if isComposite {
+			return fmt.Errorf("component id %d is a composite product and cannot be used as a component", c.ComponentID)
+		}
+		if isSerialized {
+			return fmt.Errorf("component id %d is a serialized product and cannot be used as a component", c.ComponentID)
+		}
+
+		_, err = tx.Exec("INSERT INTO product_components (product_id, component_id, quantity) VALUES ($1, $2, $3)",
+			id, c.ComponentID, c.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetLowStock - produk yang stoknya sudah di bawah atau sama dengan min_stock (produk komposit tidak ikut)
+func (repo *ProductRepository) GetLowStock() ([]models.Product, error) {
+	query := `SELECT ` + productColumns("p.stock") + `
+	          FROM products p
+	          LEFT JOIN categories c ON p.category_id = c.id
+	          WHERE p.min_stock > 0 AND p.stock <= p.min_stock AND p.archived_at IS NULL
+	            AND NOT EXISTS (SELECT 1 FROM product_components pc WHERE pc.product_id = p.id)
+	          ORDER BY p.stock - p.min_stock, p.id`
+
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, *p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// GetReorderSuggestions - saran pemesanan ulang per supplier.
+//
+// Kecepatan penjualan dihitung dari transaction_details (ditambah pemakaian sebagai komponen)
+// selama `days` hari terakhir. Produk disarankan dipesan kalau stoknya <= min_stock atau
+// days-of-cover kurang dari coverDays. Jumlah saran cukup untuk coverDays hari ditambah
+// min_stock, minimal reorder_qty. Supplier diambil dari products.supplier_id, kalau kosong
+// dari penerimaan barang terakhir.
+func (repo *ProductRepository) GetReorderSuggestions(days, coverDays int) ([]models.ReorderSupplierGroup, error) {
+	query := `WITH sales AS (
+	              SELECT td.product_id, td.quantity
+	              FROM transaction_details td
+	              JOIN transactions t ON td.transaction_id = t.id
+	              WHERE t.created_at >= NOW() - make_interval(days => $1)
+	              UNION ALL
+	              SELECT u.component_product_id, u.quantity
+	              FROM transaction_component_usage u
+	              JOIN transactions t ON u.transaction_id = t.id
+	              WHERE t.created_at >= NOW() - make_interval(days => $1)
+	          ), last_supplier AS (
+	              SELECT DISTINCT ON (grl.product_id) grl.product_id, po.supplier_id
+	              FROM goods_receipt_lines grl
+	              JOIN goods_receipts gr ON grl.goods_receipt_id = gr.id
+	              JOIN purchase_orders po ON gr.purchase_order_id = po.id
+	              ORDER BY grl.product_id, gr.received_at DESC
+	          )
+	          SELECT p.id, p.name, p.stock, p.min_stock, p.reorder_qty, p.cost_price,
+	                 COALESCE(s.id, 0), COALESCE(s.name, ''),
+	                 COALESCE((SELECT SUM(quantity) FROM sales WHERE sales.product_id = p.id), 0)
+	          FROM products p
+	          LEFT JOIN last_supplier ls ON ls.product_id = p.id
+	          LEFT JOIN suppliers s ON s.id = COALESCE(p.supplier_id, ls.supplier_id)
+	          WHERE p.archived_at IS NULL
+	            AND NOT EXISTS (SELECT 1 FROM product_components pc WHERE pc.product_id = p.id)
+	          ORDER BY COALESCE(s.id, 0), p.id`
+
+	rows, err := repo.db.Query(query, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]models.ReorderSupplierGroup, 0)
+	for rows.Next() {
+		var s models.ReorderSuggestion
+		var costPrice, supplierID int
+		var supplierName string
+		err := rows.Scan(&s.ProductID, &s.Name, &s.Stock, &s.MinStock, &s.ReorderQty, &costPrice,
+			&supplierID, &supplierName, &s.SoldQuantity)
+		if err != nil {
+			return nil, err
+		}
+
+		s.AverageDailySales = math.Round(float64(s.SoldQuantity)/float64(days)*100) / 100
+		if s.SoldQuantity > 0 {
+			cover := math.Round(float64(s.Stock)/(float64(s.SoldQuantity)/float64(days))*10) / 10
+			s.DaysOfCover = &cover
+		}
+
+		belowMin := s.MinStock > 0 && s.Stock <= s.MinStock
+		lowCover := s.DaysOfCover != nil && *s.DaysOfCover < float64(coverDays)
+		if !belowMin && !lowCover {
+			continue
+		}
+
+		target := int(math.Ceil(float64(s.SoldQuantity)/float64(days)*float64(coverDays))) + s.MinStock
+		s.SuggestedQuantity = max(target-s.Stock, s.ReorderQty)
+		if s.SuggestedQuantity <= 0 {
+			continue
+		}
+		s.EstimatedCost = int64(s.SuggestedQuantity) * int64(costPrice)
+
+		if len(groups) == 0 || groups[len(groups)-1].SupplierID != supplierID {
+			groups = append(groups, models.ReorderSupplierGroup{
+				SupplierID:   supplierID,
+				SupplierName: supplierName,
+				Items:        make([]models.ReorderSuggestion, 0),
+			})
+		}
+		group := &groups[len(groups)-1]
+		group.Items = append(group.Items, s)
+		group.TotalEstimatedCost += s.EstimatedCost
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// SetImageKey - ganti key foto produk (kosong = hapus foto), mengembalikan key lama supaya file lama bisa dihapus
+func (repo *ProductRepository) SetImageKey(id int, key string) (string, error) {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var oldKey string
+	err = tx.QueryRow("SELECT COALESCE(image_key, '') FROM products WHERE id = $1 FOR UPDATE", id).Scan(&oldKey)
+	if err == sql.ErrNoRows {
+		return "", errors.New("produk tidak ditemukan")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec("UPDATE products SET image_key = NULLIF($1, '') WHERE id = $2", key, id)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return oldKey, nil
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"kasir/models"
+	"strings"
+	"unicode"
+)
+
+// Search - cari produk aktif by nama, SKU, barcode dan nama kategori.
+//
+// Kandidat diambil dari full-text search dengan prefix matching (tiap kata jadi "kata:*", untuk
+// as-you-type di POS), kemiripan trigram (tahan typo), prefix SKU dan barcode yang sama persis.
+// Skor = relevansi teks + kemiripan trigram + bonus SKU/barcode persis, lalu ditambah
+// log(1 + terjual 30 hari) supaya produk laris naik ke atas untuk relevansi yang setara.
+func (repo *ProductRepository) Search(q string, limit int) ([]models.ProductSearchHit, error) {
+	q = strings.TrimSpace(q)
+	hits := make([]models.ProductSearchHit, 0)
+	if q == "" {
+		return hits, nil
+	}
+
+	// Kandidat dikumpulkan lewat UNION supaya tiap cabang memakai indexnya sendiri (GIN tsvector,
+	// trigram nama/SKU/kategori, unique barcode); OR lintas tabel membuat planner jatuh ke seq scan.
+	query := `WITH candidates AS (
+	              SELECT id FROM products WHERE $2 <> '' AND search_vector @@ to_tsquery('simple', $2)
+	              UNION
+	              SELECT id FROM products WHERE $1 <% name
+	              UNION
+	              SELECT id FROM products WHERE sku ILIKE $3
+	              UNION
+	              SELECT id FROM products WHERE barcode = $1
+	              UNION
+	              SELECT p.id FROM categories c JOIN products p ON p.category_id = c.id WHERE $1 <% c.name
+	          ), sales AS (
+	              SELECT td.product_id, SUM(td.quantity) AS sold
+	              FROM transaction_details td
+	              JOIN transactions t ON td.transaction_id = t.id
+	              WHERE t.created_at >= NOW() - INTERVAL '30 days'
+	                AND td.product_id IN (SELECT id FROM candidates)
+	              GROUP BY td.product_id
+	          ), scored AS (
+	              SELECT p.id,
+	                     COALESCE(s.sold, 0) AS sold,
+	                     CASE WHEN $2 = '' THEN 0 ELSE ts_rank(p.search_vector, to_tsquery('simple', $2)) END
+	                       + word_similarity($1, p.name)
+	                       + COALESCE(word_similarity($1, c.name), 0) * 0.5
+	                       + CASE WHEN lower(p.sku) = lower($1) OR p.barcode = $1 THEN 2 ELSE 0 END
+	                       + CASE WHEN lower(p.sku) LIKE lower($3) THEN 1 ELSE 0 END AS relevance
+	              FROM candidates cand
+	              JOIN products p ON p.id = cand.id
+	              LEFT JOIN categories c ON p.category_id = c.id
+	              LEFT JOIN sales s ON s.product_id = p.id
+	              WHERE p.archived_at IS NULL
+	          )
+	          SELECT ` + productColumns("p.stock") + `, (sc.relevance + ln(1 + sc.sold))::float8 AS score, sc.sold
+	          FROM scored sc
+	          JOIN products p ON p.id = sc.id
+	          LEFT JOIN categories c ON p.category_id = c.id
+	          ORDER BY score DESC, p.name, p.id
+	          LIMIT $4`
+
+	rows, err := repo.db.Query(query, q, prefixTSQuery(q), escapeLike(q)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hit models.ProductSearchHit
+		p, err := scanProduct(searchHitScanner{rows, &hit})
+		if err != nil {
+			return nil, err
+		}
+		hit.Product = *p
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hits, nil
+}
+
+// searchHitScanner - tambahkan kolom skor dan jumlah terjual setelah kolom produk
+type searchHitScanner struct {
+	row rowScanner
+	hit *models.ProductSearchHit
+}
+
+func (s searchHitScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, &s.hit.Score, &s.hit.SoldQuantity)...)
+}
+
+// prefixTSQuery - "kopi sus" -> "kopi:* & sus:*"; karakter selain huruf/angka dibuang
+// supaya input kasir tidak pernah menghasilkan tsquery yang invalid
+func prefixTSQuery(q string) string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
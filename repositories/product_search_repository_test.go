@@ -0,0 +1,27 @@
+package repositories
+
+import "testing"
+
+func TestPrefixTSQuery(t *testing.T) {
+	tests := []struct {
+		q    string
+		want string
+	}{
+		{"kopi", "kopi:*"},
+		{"kopi sus", "kopi:* & sus:*"},
+		{"  KOPI   Susu ", "kopi:* & susu:*"},
+		{"teh-botol 350ml", "teh:* & botol:* & 350ml:*"},
+		{"kopi & (susu | !gula)", "kopi:* & susu:* & gula:*"},
+		{"o'clock:*", "o:* & clock:*"},
+		{"crème brûlée", "crème:* & brûlée:*"},
+		{"", ""},
+		{"&|!():*'", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.q, func(t *testing.T) {
+			if got := prefixTSQuery(tt.q); got != tt.want {
+				t.Errorf("prefixTSQuery(%q) = %q, want %q", tt.q, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,414 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir/models"
+)
+
+type PurchaseOrderRepository struct {
+	db          *sql.DB
+	productRepo *ProductRepository
+}
+
+func NewPurchaseOrderRepository(db *sql.DB, productRepo *ProductRepository) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{db: db, productRepo: productRepo}
+}
+
+func (repo *PurchaseOrderRepository) GetAll(status string) ([]models.PurchaseOrder, error) {
+	query := `SELECT po.id, po.supplier_id, s.name, po.location_id, po.status, po.notes, po.created_at, po.updated_at
+	          FROM purchase_orders po
+	          JOIN suppliers s ON po.supplier_id = s.id`
+
+	args := []interface{}{}
+	if status != "" {
+		query += " WHERE po.status = $1"
+		args = append(args, status)
+	}
+
+	query += " ORDER BY po.id DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]models.PurchaseOrder, 0)
+	for rows.Next() {
+		var po models.PurchaseOrder
+		var supplierName string
+		var locationID sql.NullInt64
+		var notes sql.NullString
+		err := rows.Scan(&po.ID, &po.SupplierID, &supplierName, &locationID, &po.Status, &notes, &po.CreatedAt, &po.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		po.LocationID = nullIntPtr(locationID)
+		po.Notes = notes.String
+		po.Supplier = &models.Supplier{ID: po.SupplierID, Name: supplierName}
+		orders = append(orders, po)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// GetByID - ambil purchase order beserta line-nya
+func (repo *PurchaseOrderRepository) GetByID(id int) (*models.PurchaseOrder, error) {
+	query := `SELECT po.id, po.supplier_id, s.name, po.location_id, po.status, po.notes, po.created_at, po.updated_at
+	          FROM purchase_orders po
+	          JOIN suppliers s ON po.supplier_id = s.id
+	          WHERE po.id = $1`
+
+	var po models.PurchaseOrder
+	var supplierName string
+	var locationID sql.NullInt64
+	var notes sql.NullString
+	err := repo.db.QueryRow(query, id).Scan(&po.ID, &po.SupplierID, &supplierName, &locationID, &po.Status, &notes, &po.CreatedAt, &po.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("purchase order tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+	po.LocationID = nullIntPtr(locationID)
+	po.Notes = notes.String
+	po.Supplier = &models.Supplier{ID: po.SupplierID, Name: supplierName}
+
+	linesQuery := `SELECT l.id, l.purchase_order_id, l.product_id, p.name, l.quantity, l.received_quantity, l.unit_cost
+	               FROM purchase_order_lines l
+	               JOIN products p ON l.product_id = p.id
+	               WHERE l.purchase_order_id = $1
+	               ORDER BY l.id`
+
+	rows, err := repo.db.Query(linesQuery, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	po.Lines = make([]models.PurchaseOrderLine, 0)
+	for rows.Next() {
+		var l models.PurchaseOrderLine
+		err := rows.Scan(&l.ID, &l.PurchaseOrderID, &l.ProductID, &l.ProductName, &l.Quantity, &l.ReceivedQuantity, &l.UnitCost)
+		if err != nil {
+			return nil, err
+		}
+		po.Lines = append(po.Lines, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &po, nil
+}
+
+// Create - buat purchase order baru dengan status draft
+func (repo *PurchaseOrderRepository) Create(po *models.PurchaseOrder) error {
+	if err := validatePurchaseOrderLines(po.Lines); err != nil {
+		return err
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	po.Status = models.PurchaseOrderDraft
+	query := "INSERT INTO purchase_orders (supplier_id, location_id, status, notes) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at"
+	err = tx.QueryRow(query, po.SupplierID, po.LocationID, po.Status, po.Notes).Scan(&po.ID, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPurchaseOrderLines(tx, po); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update - ubah supplier, catatan dan line; hanya untuk purchase order draft
+func (repo *PurchaseOrderRepository) Update(po *models.PurchaseOrder) error {
+	if err := validatePurchaseOrderLines(po.Lines); err != nil {
+		return err
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow("SELECT status FROM purchase_orders WHERE id = $1 FOR UPDATE", po.ID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return errors.New("purchase order tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+
+	if status != models.PurchaseOrderDraft {
+		return fmt.Errorf("purchase order %d cannot be edited in status %s", po.ID, status)
+	}
+
+	query := "UPDATE purchase_orders SET supplier_id = $1, location_id = $2, notes = $3, updated_at = NOW() WHERE id = $4 RETURNING status, created_at, updated_at"
+	err = tx.QueryRow(query, po.SupplierID, po.LocationID, po.Notes, po.ID).Scan(&po.Status, &po.CreatedAt, &po.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM purchase_order_lines WHERE purchase_order_id = $1", po.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := insertPurchaseOrderLines(tx, po); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateStatus - kirim (draft -> sent) atau batalkan (draft/sent -> cancelled) purchase order
+func (repo *PurchaseOrderRepository) UpdateStatus(id int, status string) error {
+	allowedFrom := map[string][]string{
+		models.PurchaseOrderSent:      {models.PurchaseOrderDraft},
+		models.PurchaseOrderCancelled: {models.PurchaseOrderDraft, models.PurchaseOrderSent},
+	}
+
+	from, ok := allowedFrom[status]
+	if !ok {
+		return fmt.Errorf("invalid purchase order status: %s", status)
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current string
+	err = tx.QueryRow("SELECT status FROM purchase_orders WHERE id = $1 FOR UPDATE", id).Scan(&current)
+	if err == sql.ErrNoRows {
+		return errors.New("purchase order tidak ditemukan")
+	}
+	if err != nil {
+		return err
+	}
+
+	allowed := false
+	for _, s := range from {
+		if s == current {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("cannot change purchase order %d from %s to %s", id, current, status)
+	}
+
+	_, err = tx.Exec("UPDATE purchase_orders SET status = $1, updated_at = NOW() WHERE id = $2", status, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Receive - catat penerimaan barang untuk purchase order dan tambah stok produk
+func (repo *PurchaseOrderRepository) Receive(id int, req models.ReceiveRequest) (*models.GoodsReceipt, error) {
+	if len(req.Items) == 0 {
+		return nil, errors.New("receive items cannot be empty")
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var locationID int
+	err = tx.QueryRow("SELECT status, COALESCE(location_id, 0) FROM purchase_orders WHERE id = $1 FOR UPDATE", id).
+		Scan(&status, &locationID)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("purchase order tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status != models.PurchaseOrderSent && status != models.PurchaseOrderPartiallyReceived {
+		return nil, fmt.Errorf("purchase order %d cannot be received in status %s", id, status)
+	}
+
+	receipt := models.GoodsReceipt{
+		PurchaseOrderID: id,
+		Notes:           req.Notes,
+		Lines:           make([]models.GoodsReceiptLine, 0, len(req.Items)),
+	}
+
+	err = tx.QueryRow("INSERT INTO goods_receipts (purchase_order_id, notes) VALUES ($1, $2) RETURNING id, received_at",
+		id, req.Notes).Scan(&receipt.ID, &receipt.ReceivedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity must be greater than 0 for line id %d", item.LineID)
+		}
+
+		var productID, ordered, received, unitCost int
+		var trackExpiry, trackSerial bool
+		err := tx.QueryRow(`SELECT l.product_id, l.quantity, l.received_quantity, l.unit_cost, p.track_expiry, p.track_serial
+		                    FROM purchase_order_lines l
+		                    JOIN products p ON l.product_id = p.id
+		                    WHERE l.id = $1 AND l.purchase_order_id = $2`, item.LineID, id).
+			Scan(&productID, &ordered, &received, &unitCost, &trackExpiry, &trackSerial)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("line id %d not found in purchase order %d", item.LineID, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if received+item.Quantity > ordered {
+			return nil, fmt.Errorf("over receipt for line id %d: ordered %d, already received %d, receiving %d",
+				item.LineID, ordered, received, item.Quantity)
+		}
+
+		if trackExpiry && item.ExpiryDate == "" {
+			return nil, fmt.Errorf("expiry date is required for product id %d (line id %d)", productID, item.LineID)
+		}
+
+		if trackSerial {
+			if err := validateSerialNumbers(productID, item.Quantity, item.SerialNumbers); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = tx.Exec("UPDATE purchase_order_lines SET received_quantity = received_quantity + $1 WHERE id = $2",
+			item.Quantity, item.LineID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := repo.productRepo.ReceiveStock(tx, productID, item.Quantity, unitCost); err != nil {
+			return nil, err
+		}
+
+		if locationID > 0 {
+			if err := adjustLocationStock(tx, productID, locationID, item.Quantity); err != nil {
+				return nil, err
+			}
+		}
+
+		line := models.GoodsReceiptLine{
+			LineID:    item.LineID,
+			ProductID: productID,
+			Quantity:  item.Quantity,
+			UnitCost:  unitCost,
+		}
+		err = tx.QueryRow(`INSERT INTO goods_receipt_lines (goods_receipt_id, purchase_order_line_id, product_id, quantity, unit_cost)
+		                   VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			receipt.ID, line.LineID, line.ProductID, line.Quantity, line.UnitCost).Scan(&line.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if trackSerial {
+			if err := registerSerials(tx, productID, line.ID, item.SerialNumbers); err != nil {
+				return nil, err
+			}
+			line.SerialNumbers = item.SerialNumbers
+		}
+
+		if trackExpiry {
+			line.BatchID, err = createBatch(tx, productID, locationID, line.ID, item.Quantity, item.BatchNumber, item.ExpiryDate)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		receipt.Lines = append(receipt.Lines, line)
+	}
+
+	// Status jadi received kalau semua line sudah diterima penuh
+	var outstanding int
+	err = tx.QueryRow("SELECT COUNT(*) FROM purchase_order_lines WHERE purchase_order_id = $1 AND received_quantity < quantity", id).
+		Scan(&outstanding)
+	if err != nil {
+		return nil, err
+	}
+
+	newStatus := models.PurchaseOrderPartiallyReceived
+	if outstanding == 0 {
+		newStatus = models.PurchaseOrderReceived
+	}
+
+	_, err = tx.Exec("UPDATE purchase_orders SET status = $1, updated_at = NOW() WHERE id = $2", newStatus, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+func validatePurchaseOrderLines(lines []models.PurchaseOrderLine) error {
+	if len(lines) == 0 {
+		return errors.New("purchase order lines cannot be empty")
+	}
+
+	for _, l := range lines {
+		if l.ProductID <= 0 {
+			return fmt.Errorf("invalid product id: %d", l.ProductID)
+		}
+		if l.Quantity <= 0 {
+			return fmt.Errorf("invalid quantity for product %d: %d", l.ProductID, l.Quantity)
+		}
+		if l.UnitCost < 0 {
+			return fmt.Errorf("invalid unit cost for product %d: %d", l.ProductID, l.UnitCost)
+		}
+	}
+
+	return nil
+}
+
+func insertPurchaseOrderLines(tx *sql.Tx, po *models.PurchaseOrder) error {
+	query := `INSERT INTO purchase_order_lines (purchase_order_id, product_id, quantity, unit_cost)
+	          VALUES ($1, $2, $3, $4) RETURNING id`
+
+	for i := range po.Lines {
+		l := &po.Lines[i]
+		l.PurchaseOrderID = po.ID
+		l.ReceivedQuantity = 0
+		err := tx.QueryRow(query, po.ID, l.ProductID, l.Quantity, l.UnitCost).Scan(&l.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	id := int(v.Int64)
+	return &id
+}
@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir/models"
+	"time"
+)
+
+type ReportRepository struct {
+	db       *sql.DB
+	calendar *models.BusinessCalendar
+}
+
+func NewReportRepository(db *sql.DB, calendar *models.BusinessCalendar) *ReportRepository {
+	return &ReportRepository{db: db, calendar: calendar}
+}
+
+// bucketFormats - label bucket waktu (format Go) per pengelompokan
+var bucketFormats = map[string]string{
+	models.SalesByHour:  "2006-01-02 15:00",
+	models.SalesByDay:   "2006-01-02",
+	models.SalesByWeek:  "2006-01-02",
+	models.SalesByMonth: "2006-01",
+}
+
+// periodFilter - kondisi WHERE periode laporan atas transactions t, parameter mulai dari $1.
+// Batas periode berupa instant (sudah memperhitungkan zona waktu toko dan cutoff hari bisnis),
+// jadi tidak bergantung pada timezone database.
+func periodFilter(period models.ReportPeriod) (string, []interface{}) {
+	whereClause := "WHERE t.total_amount IS NOT NULL"
+	params := []interface{}{}
+
+	if !period.From.IsZero() {
+		params = append(params, period.From)
+		whereClause += fmt.Sprintf(" AND t.created_at >= $%d", len(params))
+	}
+	if !period.To.IsZero() {
+		params = append(params, period.To)
+		whereClause += fmt.Sprintf(" AND t.created_at < $%d", len(params))
+	}
+	if period.OutletID > 0 {
+		params = append(params, period.OutletID)
+		whereClause += fmt.Sprintf(" AND t.outlet_id = $%d", len(params))
+	}
+
+	return whereClause, params
+}
+
+// GetSalesBreakdown - revenue, quantity dan jumlah transaksi per kelompok.
+//
+// Per produk: semua produk aktif ikut walaupun tidak terjual (supaya bottom-N memuat produk yang
+// tidak laku), produk arsip hanya kalau terjual di periode. Per kategori: kategori aktif plus
+// "Uncategorized" kalau ada penjualannya. Per bucket waktu: hanya bucket yang ada transaksinya,
+// urut kronologis. Baris produk/kategori urut revenue tertinggi.
+func (repo *ReportRepository) GetSalesBreakdown(groupBy string, period models.ReportPeriod) ([]models.SalesBreakdownRow, error) {
+	whereClause, params := periodFilter(period)
+
+	var query string
+	switch groupBy {
+	case models.SalesByProduct:
+		query = fmt.Sprintf(`
+			WITH sales AS (
+				SELECT td.product_id, SUM(td.subtotal)::bigint AS revenue, SUM(td.quantity)::bigint AS quantity,
+				       COUNT(DISTINCT t.id) AS transactions
+				FROM transaction_details td
+				JOIN transactions t ON td.transaction_id = t.id
+				%s
+				GROUP BY td.product_id
+			)
+			SELECT p.id, p.name, NULL::timestamptz, COALESCE(s.revenue, 0), COALESCE(s.quantity, 0), COALESCE(s.transactions, 0)
+			FROM products p
+			LEFT JOIN sales s ON s.product_id = p.id
+			WHERE p.archived_at IS NULL OR s.product_id IS NOT NULL
+			ORDER BY 4 DESC, p.name, p.id`, whereClause)
+	case models.SalesByCategory:
+		query = fmt.Sprintf(`
+			WITH sales AS (
+				SELECT COALESCE(p.category_id, 0) AS category_id, SUM(td.subtotal)::bigint AS revenue,
+				       SUM(td.quantity)::bigint AS quantity, COUNT(DISTINCT t.id) AS transactions
+				FROM transaction_details td
+				JOIN transactions t ON td.transaction_id = t.id
+				JOIN products p ON td.product_id = p.id
+				%s
+				GROUP BY 1
+			), groups AS (
+				SELECT id, name FROM categories
+				WHERE archived_at IS NULL OR id IN (SELECT category_id FROM sales)
+				UNION ALL
+				SELECT 0, 'Uncategorized' WHERE EXISTS (SELECT 1 FROM sales WHERE category_id = 0)
+			)
+			SELECT g.id, g.name, NULL::timestamptz, COALESCE(s.revenue, 0), COALESCE(s.quantity, 0), COALESCE(s.transactions, 0)
+			FROM groups g
+			LEFT JOIN sales s ON s.category_id = g.id
+			ORDER BY 4 DESC, g.name, g.id`, whereClause)
+	case models.SalesByHour, models.SalesByDay, models.SalesByWeek, models.SalesByMonth:
+		// Bucket dihitung di jam lokal toko; bucket hari/minggu/bulan digeser cutoff hari bisnis
+		// supaya penjualan jam 02:00 dengan cutoff 04:00 masuk ke hari sebelumnya.
+		// groupBy sudah divalidasi, aman dipakai sebagai field date_trunc.
+		cutoff := repo.calendar.Cutoff
+		if groupBy == models.SalesByHour {
+			cutoff = 0
+		}
+		params = append(params, repo.calendar.Location.String(), int(cutoff.Seconds()))
+		query = fmt.Sprintf(`
+			SELECT 0, '', date_trunc('%s', (t.created_at AT TIME ZONE $%d) - make_interval(secs => $%d::int)) AS bucket,
+			       SUM(td.subtotal)::bigint, SUM(td.quantity)::bigint, COUNT(DISTINCT t.id)
+			FROM transaction_details td
+			JOIN transactions t ON td.transaction_id = t.id
+			%s
+			GROUP BY bucket
+			ORDER BY bucket`, groupBy, len(params)-1, len(params), whereClause)
+	default:
+		return nil, fmt.Errorf("invalid group_by: %q", groupBy)
+	}
+
+	rows, err := repo.db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]models.SalesBreakdownRow, 0)
+	for rows.Next() {
+		var row models.SalesBreakdownRow
+		var bucket sql.NullTime
+		err := rows.Scan(&row.ID, &row.Name, &bucket, &row.Revenue, &row.Quantity, &row.Transactions)
+		if err != nil {
+			return nil, err
+		}
+		if bucket.Valid {
+			start := repo.bucketStart(groupBy, bucket.Time)
+			row.BucketStart = &start
+			row.Name = bucket.Time.Format(bucketFormats[groupBy])
+		}
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// bucketStart - instant awal bucket dari jam dinding lokal hasil date_trunc (timestamp tanpa zona).
+// Bucket hari/minggu/bulan mulai jam cutoff lokal lewat DayStart, sama dengan batas filter periode
+// di hari pergantian DST.
+func (repo *ReportRepository) bucketStart(groupBy string, wall time.Time) time.Time {
+	if groupBy == models.SalesByHour {
+		return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), 0, 0, 0, repo.calendar.Location)
+	}
+	return repo.calendar.DayStart(wall)
+}
+
+// GetZReport - total penjualan satu periode (biasanya satu hari bisnis) untuk Z-report
+func (repo *ReportRepository) GetZReport(period models.ReportPeriod) (*models.ZReport, error) {
+	whereClause, params := periodFilter(period)
+
+	report := &models.ZReport{
+		OpenedAt: period.From.In(repo.calendar.Location),
+		ClosedAt: period.To.In(repo.calendar.Location),
+		OutletID: period.OutletID,
+	}
+
+	var first, last sql.NullTime
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(t.total_amount), 0)::bigint, MIN(t.created_at), MAX(t.created_at)
+		FROM transactions t ` + whereClause
+	err := repo.db.QueryRow(query, params...).Scan(&report.TotalTransactions, &report.GrossSales, &first, &last)
+	if err != nil {
+		return nil, err
+	}
+	if first.Valid {
+		t := first.Time.In(repo.calendar.Location)
+		report.FirstTransactionAt = &t
+	}
+	if last.Valid {
+		t := last.Time.In(repo.calendar.Location)
+		report.LastTransactionAt = &t
+	}
+
+	itemsQuery := `
+		SELECT COALESCE(SUM(td.quantity), 0)::bigint, COALESCE(SUM(td.cost_price::bigint * td.quantity), 0)::bigint
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id ` + whereClause
+	err = repo.db.QueryRow(itemsQuery, params...).Scan(&report.TotalItems, &report.TotalCOGS)
+	if err != nil {
+		return nil, err
+	}
+	report.GrossProfit = report.GrossSales - report.TotalCOGS
+	report.AverageBasketValue = models.Ratio(report.GrossSales, int64(report.TotalTransactions))
+
+	if report.ByCategory, err = repo.GetSalesBreakdown(models.SalesByCategory, period); err != nil {
+		return nil, err
+	}
+	if report.ByHour, err = repo.GetSalesBreakdown(models.SalesByHour, period); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// EachTransactionLine - panggil fn untuk setiap item transaksi dalam periode, urut waktu transaksi.
+// Baris dibaca satu per satu dari cursor sehingga export besar tidak dimuat ke memori sekaligus.
+func (repo *ReportRepository) EachTransactionLine(period models.ReportPeriod, fn func(models.TransactionLine) error) error {
+	whereClause, params := periodFilter(period)
+	query := `
+		SELECT t.id, t.created_at, t.outlet_id, COALESCE(t.customer_name, ''),
+		       td.product_id, COALESCE(p.sku, ''), p.name, td.quantity, td.subtotal, td.cost_price::bigint * td.quantity
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		JOIN products p ON td.product_id = p.id
+		` + whereClause + `
+		ORDER BY t.created_at, t.id, td.id`
+
+	rows, err := repo.db.Query(query, params...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.TransactionLine
+		var outletID sql.NullInt64
+		err := rows.Scan(&line.TransactionID, &line.CreatedAt, &outletID, &line.CustomerName,
+			&line.ProductID, &line.SKU, &line.ProductName, &line.Quantity, &line.Subtotal, &line.COGS)
+		if err != nil {
+			return err
+		}
+		line.CreatedAt = line.CreatedAt.In(repo.calendar.Location)
+		line.BusinessDate = repo.calendar.BusinessDate(line.CreatedAt).Format("2006-01-02")
+		line.OutletID = nullIntPtr(outletID)
+
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
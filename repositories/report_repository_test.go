@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"kasir/models"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestReportRepositoryBucketStart(t *testing.T) {
+	calendar, err := models.NewBusinessCalendar("America/New_York", "04:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewReportRepository(nil, calendar)
+
+	wall := func(value string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name    string
+		groupBy string
+		wall    string
+		want    string // RFC3339
+	}{
+		{"day in winter", models.SalesByDay, "2026-01-15 00:00", "2026-01-15T09:00:00Z"},
+		{"day in summer", models.SalesByDay, "2026-07-15 00:00", "2026-07-15T08:00:00Z"},
+		// DST mulai 2026-03-08 02:00: hari bisnis tetap mulai 04:00 EDT, bukan 05:00
+		{"spring forward day", models.SalesByDay, "2026-03-08 00:00", "2026-03-08T08:00:00Z"},
+		// DST selesai 2026-11-01 02:00: hari bisnis mulai 04:00 EST
+		{"fall back day", models.SalesByDay, "2026-11-01 00:00", "2026-11-01T09:00:00Z"},
+		{"week starting on spring forward", models.SalesByWeek, "2026-03-09 00:00", "2026-03-09T08:00:00Z"},
+		{"month across spring forward", models.SalesByMonth, "2026-03-01 00:00", "2026-03-01T09:00:00Z"},
+		{"hour after spring forward", models.SalesByHour, "2026-03-08 03:00", "2026-03-08T07:00:00Z"},
+		{"hour before fall back", models.SalesByHour, "2026-11-01 00:00", "2026-11-01T04:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := time.Parse(time.RFC3339, tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := repo.bucketStart(tt.groupBy, wall(tt.wall))
+			if !got.Equal(want) {
+				t.Errorf("bucketStart(%s, %s) = %v, want %v", tt.groupBy, tt.wall, got.UTC(), want)
+			}
+
+			if tt.groupBy != models.SalesByHour {
+				// awal bucket harus sama dengan batas periode hari bisnis yang sama
+				day := calendar.DayPeriod(wall(tt.wall))
+				if !got.Equal(day.From) {
+					t.Errorf("bucketStart = %v, DayPeriod.From = %v", got.UTC(), day.From.UTC())
+				}
+			}
+		})
+	}
+}
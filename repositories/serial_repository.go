@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir/models"
+	"strings"
+)
+
+type SerialRepository struct {
+	db *sql.DB
+}
+
+func NewSerialRepository(db *sql.DB) *SerialRepository {
+	return &SerialRepository{db: db}
+}
+
+const serialColumns = `s.id, s.product_id, p.name, s.serial_number, s.status, gr.purchase_order_id, s.received_at,
+	                   s.transaction_id, s.sold_at, t.customer_name, t.customer_phone`
+
+const serialJoins = `FROM product_serials s
+	                 JOIN products p ON s.product_id = p.id
+	                 LEFT JOIN goods_receipt_lines grl ON s.goods_receipt_line_id = grl.id
+	                 LEFT JOIN goods_receipts gr ON grl.goods_receipt_id = gr.id
+	                 LEFT JOIN transactions t ON s.transaction_id = t.id`
+
+// GetBySerialNumber - cari unit by nomor serial (bisa lebih dari satu kalau produk berbeda memakai serial yang sama)
+func (repo *SerialRepository) GetBySerialNumber(serialNumber string) ([]models.ProductSerial, error) {
+	return repo.query(`SELECT `+serialColumns+` `+serialJoins+`
+	                   WHERE s.serial_number = $1
+	                   ORDER BY s.id`, strings.TrimSpace(serialNumber))
+}
+
+// GetByProduct - unit serial satu produk, bisa difilter status (kosong = semua)
+func (repo *SerialRepository) GetByProduct(productID int, status string) ([]models.ProductSerial, error) {
+	return repo.query(`SELECT `+serialColumns+` `+serialJoins+`
+	                   WHERE s.product_id = $1 AND ($2 = '' OR s.status = $2)
+	                   ORDER BY s.serial_number`, productID, status)
+}
+
+func (repo *SerialRepository) query(query string, args ...interface{}) ([]models.ProductSerial, error) {
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	serials := make([]models.ProductSerial, 0)
+	for rows.Next() {
+		var s models.ProductSerial
+		var purchaseOrderID, transactionID sql.NullInt64
+		var soldAt sql.NullTime
+		var customerName, customerPhone sql.NullString
+		err := rows.Scan(&s.ID, &s.ProductID, &s.ProductName, &s.SerialNumber, &s.Status, &purchaseOrderID, &s.ReceivedAt,
+			&transactionID, &soldAt, &customerName, &customerPhone)
+		if err != nil {
+			return nil, err
+		}
+		s.PurchaseOrderID = nullIntPtr(purchaseOrderID)
+		s.TransactionID = nullIntPtr(transactionID)
+		if soldAt.Valid {
+			s.SoldAt = &soldAt.Time
+		}
+		s.CustomerName = customerName.String
+		s.CustomerPhone = customerPhone.String
+		serials = append(serials, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return serials, nil
+}
+
+// validateSerialNumbers - jumlah serial harus sama dengan quantity dan tidak boleh ada yang dobel.
+// Serial di-trim di tempat (slice milik request ikut berubah), jadi yang disimpan, dikunci dan dicari
+// selalu bentuk yang sama dengan yang dipakai GetBySerialNumber.
+func validateSerialNumbers(productID, quantity int, serials []string) error {
+	if len(serials) != quantity {
+		return fmt.Errorf("serial numbers are required for product id %d: expected %d, got %d", productID, quantity, len(serials))
+	}
+
+	seen := make(map[string]bool, len(serials))
+	for i, serial := range serials {
+		serial = strings.TrimSpace(serial)
+		serials[i] = serial
+		if serial == "" {
+			return fmt.Errorf("invalid serial number for product id %d: empty", productID)
+		}
+		if seen[serial] {
+			return fmt.Errorf("invalid serial number for product id %d: %q is listed more than once", productID, serial)
+		}
+		seen[serial] = true
+	}
+
+	return nil
+}
+
+// registerSerials - daftarkan unit serial dari penerimaan barang
+func registerSerials(tx *sql.Tx, productID, receiptLineID int, serials []string) error {
+	for _, serial := range serials {
+		var exists bool
+		err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM product_serials WHERE product_id = $1 AND serial_number = $2)",
+			productID, serial).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("invalid serial number for product id %d: %q is already registered", productID, serial)
+		}
+
+		_, err = tx.Exec(`INSERT INTO product_serials (product_id, serial_number, status, goods_receipt_line_id)
+		                  VALUES ($1, $2, $3, $4)`, productID, serial, models.SerialInStock, receiptLineID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lockSerials - kunci unit serial yang akan dijual, semuanya harus milik produk ini dan masih in_stock
+func lockSerials(tx *sql.Tx, productID int, serials []string) error {
+	for _, serial := range serials {
+		var status string
+		err := tx.QueryRow("SELECT status FROM product_serials WHERE product_id = $1 AND serial_number = $2 FOR UPDATE",
+			productID, serial).Scan(&status)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("serial number %q not found for product id %d", serial, productID)
+		}
+		if err != nil {
+			return err
+		}
+		if status != models.SerialInStock {
+			return fmt.Errorf("serial number %q for product id %d cannot be sold: status %s", serial, productID, status)
+		}
+	}
+
+	return nil
+}
+
+// markSerialsSold - tandai unit serial terjual di transaksi tertentu
+func markSerialsSold(tx *sql.Tx, transactionID, productID int, serials []string) error {
+	for _, serial := range serials {
+		_, err := tx.Exec(`UPDATE product_serials SET status = $1, transaction_id = $2, sold_at = NOW()
+		                   WHERE product_id = $3 AND serial_number = $4`,
+			models.SerialSold, transactionID, productID, serial)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
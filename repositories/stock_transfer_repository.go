@@ -0,0 +1,342 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"kasir/models"
+)
+
+type StockTransferRepository struct {
+	db *sql.DB
+}
+
+func NewStockTransferRepository(db *sql.DB) *StockTransferRepository {
+	return &StockTransferRepository{db: db}
+}
+
+const stockTransferColumns = "id, from_location_id, to_location_id, status, notes, created_at, shipped_at, received_at"
+
+func scanStockTransfer(row rowScanner) (*models.StockTransfer, error) {
+	var t models.StockTransfer
+	var notes sql.NullString
+	var shippedAt, receivedAt sql.NullTime
+	err := row.Scan(&t.ID, &t.FromLocationID, &t.ToLocationID, &t.Status, &notes, &t.CreatedAt, &shippedAt, &receivedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.Notes = notes.String
+	if shippedAt.Valid {
+		t.ShippedAt = &shippedAt.Time
+	}
+	if receivedAt.Valid {
+		t.ReceivedAt = &receivedAt.Time
+	}
+	return &t, nil
+}
+
+func (repo *StockTransferRepository) GetAll(status string) ([]models.StockTransfer, error) {
+	query := "SELECT " + stockTransferColumns + " FROM stock_transfers"
+
+	args := []interface{}{}
+	if status != "" {
+		query += " WHERE status = $1"
+		args = append(args, status)
+	}
+
+	query += " ORDER BY id DESC"
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]models.StockTransfer, 0)
+	for rows.Next() {
+		t, err := scanStockTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+// GetByID - ambil transfer stok beserta line-nya
+func (repo *StockTransferRepository) GetByID(id int) (*models.StockTransfer, error) {
+	t, err := scanStockTransfer(repo.db.QueryRow("SELECT "+stockTransferColumns+" FROM stock_transfers WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("transfer stok tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := repo.db.Query(`SELECT l.id, l.transfer_id, l.product_id, p.name, l.quantity
+	                            FROM stock_transfer_lines l
+	                            JOIN products p ON l.product_id = p.id
+	                            WHERE l.transfer_id = $1
+	                            ORDER BY l.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t.Lines = make([]models.StockTransferLine, 0)
+	for rows.Next() {
+		var l models.StockTransferLine
+		err := rows.Scan(&l.ID, &l.TransferID, &l.ProductID, &l.ProductName, &l.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		t.Lines = append(t.Lines, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Create - buat transfer stok baru dengan status draft
+func (repo *StockTransferRepository) Create(t *models.StockTransfer) error {
+	if t.FromLocationID <= 0 || t.ToLocationID <= 0 {
+		return errors.New("from_location_id and to_location_id are required")
+	}
+	if t.FromLocationID == t.ToLocationID {
+		return errors.New("cannot transfer stock to the same location")
+	}
+	if len(t.Lines) == 0 {
+		return errors.New("transfer lines cannot be empty")
+	}
+	for _, l := range t.Lines {
+		if l.ProductID <= 0 {
+			return fmt.Errorf("invalid product id: %d", l.ProductID)
+		}
+		if l.Quantity <= 0 {
+			return fmt.Errorf("invalid quantity for product %d: %d", l.ProductID, l.Quantity)
+		}
+	}
+
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	t.Status = models.TransferDraft
+	err = tx.QueryRow(`INSERT INTO stock_transfers (from_location_id, to_location_id, status, notes)
+	                   VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		t.FromLocationID, t.ToLocationID, t.Status, t.Notes).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	for i := range t.Lines {
+		l := &t.Lines[i]
+		l.TransferID = t.ID
+		err := tx.QueryRow("INSERT INTO stock_transfer_lines (transfer_id, product_id, quantity) VALUES ($1, $2, $3) RETURNING id",
+			t.ID, l.ProductID, l.Quantity).Scan(&l.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Ship - kirim transfer (draft -> in_transit), stok keluar dari lokasi asal
+func (repo *StockTransferRepository) Ship(id int) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fromID, _, err := lockTransfer(tx, id, models.TransferDraft)
+	if err != nil {
+		return err
+	}
+
+	lines, err := transferLines(tx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range lines {
+		if err := deductLocationStock(tx, l.productID, fromID, l.quantity); err != nil {
+			return err
+		}
+
+		if !l.trackExpiry {
+			continue
+		}
+
+		taken, err := deductBatchesFEFO(tx, l.productID, fromID, l.quantity)
+		if err != nil {
+			return err
+		}
+		for _, b := range taken {
+			_, err := tx.Exec(`INSERT INTO stock_transfer_batches (transfer_line_id, batch_number, expiry_date, quantity)
+			                   VALUES ($1, NULLIF($2, ''), $3, $4)`, l.id, b.batchNumber, b.expiryDate, b.quantity)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec("UPDATE stock_transfers SET status = $1, shipped_at = NOW() WHERE id = $2", models.TransferInTransit, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Receive - terima transfer (in_transit -> received), stok masuk ke lokasi tujuan
+func (repo *StockTransferRepository) Receive(id int) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, toID, err := lockTransfer(tx, id, models.TransferInTransit)
+	if err != nil {
+		return err
+	}
+
+	lines, err := transferLines(tx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range lines {
+		if err := adjustLocationStock(tx, l.productID, toID, l.quantity); err != nil {
+			return err
+		}
+	}
+
+	// Batch yang dikirim dibuat ulang di lokasi tujuan dengan nomor batch dan expiry yang sama
+	rows, err := tx.Query(`SELECT l.product_id, COALESCE(b.batch_number, ''), b.expiry_date::text, b.quantity
+	                       FROM stock_transfer_batches b
+	                       JOIN stock_transfer_lines l ON b.transfer_line_id = l.id
+	                       WHERE l.transfer_id = $1
+	                       ORDER BY b.id`, id)
+	if err != nil {
+		return err
+	}
+
+	type shippedBatch struct {
+		productID   int
+		batchNumber string
+		expiryDate  string
+		quantity    int
+	}
+	batches := make([]shippedBatch, 0)
+	for rows.Next() {
+		var b shippedBatch
+		if err := rows.Scan(&b.productID, &b.batchNumber, &b.expiryDate, &b.quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		batches = append(batches, b)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, b := range batches {
+		if _, err := createBatch(tx, b.productID, toID, 0, b.quantity, b.batchNumber, b.expiryDate); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE stock_transfers SET status = $1, received_at = NOW() WHERE id = $2", models.TransferReceived, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Cancel - batalkan transfer yang belum dikirim
+func (repo *StockTransferRepository) Cancel(id int) error {
+	tx, err := repo.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, _, err := lockTransfer(tx, id, models.TransferDraft); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE stock_transfers SET status = $1 WHERE id = $2", models.TransferCancelled, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockTransfer - kunci transfer dan pastikan statusnya sesuai
+func lockTransfer(tx *sql.Tx, id int, expectedStatus string) (fromID, toID int, err error) {
+	var status string
+	err = tx.QueryRow("SELECT from_location_id, to_location_id, status FROM stock_transfers WHERE id = $1 FOR UPDATE", id).
+		Scan(&fromID, &toID, &status)
+	if err == sql.ErrNoRows {
+		return 0, 0, errors.New("transfer stok tidak ditemukan")
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if status != expectedStatus {
+		return 0, 0, fmt.Errorf("stock transfer %d cannot be processed in status %s", id, status)
+	}
+
+	return fromID, toID, nil
+}
+
+type transferLine struct {
+	id          int
+	productID   int
+	quantity    int
+	trackExpiry bool
+}
+
+func transferLines(tx *sql.Tx, transferID int) ([]transferLine, error) {
+	rows, err := tx.Query(`SELECT l.id, l.product_id, l.quantity, p.track_expiry
+	                       FROM stock_transfer_lines l
+	                       JOIN products p ON l.product_id = p.id
+	                       WHERE l.transfer_id = $1
+	                       ORDER BY l.id`, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]transferLine, 0)
+	for rows.Next() {
+		var l transferLine
+		if err := rows.Scan(&l.id, &l.productID, &l.quantity, &l.trackExpiry); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"kasir/models"
+)
+
+type SupplierRepository struct {
+	db *sql.DB
+}
+
+func NewSupplierRepository(db *sql.DB) *SupplierRepository {
+	return &SupplierRepository{db: db}
+}
+
+func (repo *SupplierRepository) GetAll() ([]models.Supplier, error) {
+	query := "SELECT id, name, phone, email, address FROM suppliers ORDER BY id"
+	rows, err := repo.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := make([]models.Supplier, 0)
+	for rows.Next() {
+		var s models.Supplier
+		var phone, email, address sql.NullString
+		err := rows.Scan(&s.ID, &s.Name, &phone, &email, &address)
+		if err != nil {
+			return nil, err
+		}
+		s.Phone = phone.String
+		s.Email = email.String
+		s.Address = address.String
+		suppliers = append(suppliers, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suppliers, nil
+}
+
+func (repo *SupplierRepository) Create(supplier *models.Supplier) error {
+	query := "INSERT INTO suppliers (name, phone, email, address) VALUES ($1, $2, $3, $4) RETURNING id"
+	err := repo.db.QueryRow(query, supplier.Name, supplier.Phone, supplier.Email, supplier.Address).Scan(&supplier.ID)
+	return err
+}
+
+func (repo *SupplierRepository) GetByID(id int) (*models.Supplier, error) {
+	query := "SELECT id, name, phone, email, address FROM suppliers WHERE id = $1"
+
+	var s models.Supplier
+	var phone, email, address sql.NullString
+	err := repo.db.QueryRow(query, id).Scan(&s.ID, &s.Name, &phone, &email, &address)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("supplier tidak ditemukan")
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Phone = phone.String
+	s.Email = email.String
+	s.Address = address.String
+
+	return &s, nil
+}
+
+func (repo *SupplierRepository) Update(supplier *models.Supplier) error {
+	query := "UPDATE suppliers SET name = $1, phone = $2, email = $3, address = $4 WHERE id = $5"
+	result, err := repo.db.Exec(query, supplier.Name, supplier.Phone, supplier.Email, supplier.Address, supplier.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("supplier tidak ditemukan")
+	}
+
+	return nil
+}
+
+func (repo *SupplierRepository) Delete(id int) error {
+	query := "DELETE FROM suppliers WHERE id = $1"
+	result, err := repo.db.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("supplier tidak ditemukan")
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"kasir/models"
 	"strings"
+	"time"
 )
 
 type TransactionRepository struct {
@@ -15,59 +16,138 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
-func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem, useLock bool) (*models.Transaction, error) {
+func (repo *TransactionRepository) CreateTransaction(req models.CheckoutRequest, useLock bool) (*models.Transaction, error) {
 	tx, err := repo.db.Begin()
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	// Checkout dengan outlet_id mengurangi stok outlet tersebut
+	if req.OutletID > 0 {
+		var locationType string
+		err := tx.QueryRow("SELECT type FROM locations WHERE id = $1", req.OutletID).Scan(&locationType)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("outlet id %d not found", req.OutletID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if locationType != models.LocationOutlet {
+			return nil, fmt.Errorf("location id %d is not an outlet", req.OutletID)
+		}
+	}
+
 	totalAmount := 0
 	details := make([]models.TransactionDetail, 0)
+	usages := make([]componentUsage, 0)
+	soldSerials := make(map[string]bool) // product_id/serial, cegah serial yang sama dipakai di dua item
 
-	for _, item := range items {
+	for _, item := range req.Items {
 		if item.Quantity <= 0 {
 			return nil, fmt.Errorf("quantity must be greater than 0 for product id %d", item.ProductID)
 		}
 
-		var productPrice, stock int
+		var productPrice, costPrice, stock int
 		var productName string
+		var trackExpiry, trackSerial, archived bool
 
-		query := "SELECT name, price, stock FROM products WHERE id = $1"
+		query := "SELECT name, price, cost_price, stock, track_expiry, track_serial, archived_at IS NOT NULL FROM products WHERE id = $1"
 		if useLock {
 			query += " FOR UPDATE"
 		}
 
-		err := tx.QueryRow(query, item.ProductID).Scan(&productName, &productPrice, &stock)
+		err := tx.QueryRow(query, item.ProductID).Scan(&productName, &productPrice, &costPrice, &stock, &trackExpiry, &trackSerial, &archived)
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("product id %d not found", item.ProductID)
 		}
 		if err != nil {
 			return nil, err
 		}
+		if archived {
+			return nil, fmt.Errorf("product id %d is archived and cannot be sold", item.ProductID)
+		}
 
-		if stock < item.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product id %d: requested %d, available %d", item.ProductID, item.Quantity, stock)
+		// Produk serial: kasir wajib memilih unit yang dijual, satu serial per unit
+		if trackSerial {
+			if err := validateSerialNumbers(item.ProductID, item.Quantity, item.SerialNumbers); err != nil {
+				return nil, err
+			}
+			for _, serial := range item.SerialNumbers {
+				key := fmt.Sprintf("%d/%s", item.ProductID, serial)
+				if soldSerials[key] {
+					return nil, fmt.Errorf("invalid serial number for product id %d: %q is listed more than once", item.ProductID, serial)
+				}
+				soldSerials[key] = true
+			}
+			if err := lockSerials(tx, item.ProductID, item.SerialNumbers); err != nil {
+				return nil, err
+			}
+		} else if len(item.SerialNumbers) > 0 {
+			return nil, fmt.Errorf("product id %d is not serialized, serial numbers cannot be set", item.ProductID)
 		}
 
-		subtotal := productPrice * item.Quantity
-		totalAmount += subtotal
+		components, err := lockComponents(tx, item.ProductID, useLock)
+		if err != nil {
+			return nil, err
+		}
 
-		_, err = tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", item.Quantity, item.ProductID)
+		if len(components) == 0 {
+			err := deductStock(tx, item.ProductID, stock, trackExpiry, req.OutletID, item.Quantity)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			// Produk komposit: stok diambil dari komponen sesuai resep, HPP = jumlah HPP komponen
+			costPrice = 0
+			for _, c := range components {
+				consumed := c.quantity * item.Quantity
+				err := deductStock(tx, c.productID, c.stock, c.trackExpiry, req.OutletID, consumed)
+				if err != nil {
+					return nil, err
+				}
+				costPrice += c.costPrice * c.quantity
+				usages = append(usages, componentUsage{
+					bundleID:    item.ProductID,
+					componentID: c.productID,
+					quantity:    consumed,
+					costPrice:   c.costPrice,
+				})
+			}
+		}
+
+		productPrice, _, err = resolvePrice(tx, item.ProductID, productPrice, models.PriceContext{
+			OutletID:     req.OutletID,
+			CustomerTier: req.CustomerTier,
+			Quantity:     item.Quantity,
+		})
 		if err != nil {
 			return nil, err
 		}
 
+		subtotal := productPrice * item.Quantity
+		totalAmount += subtotal
+
 		details = append(details, models.TransactionDetail{
-			ProductID:   item.ProductID,
-			ProductName: productName,
-			Quantity:    item.Quantity,
-			Subtotal:    subtotal,
+			ProductID:     item.ProductID,
+			ProductName:   productName,
+			Quantity:      item.Quantity,
+			Subtotal:      subtotal,
+			CostPrice:     costPrice,
+			SerialNumbers: item.SerialNumbers,
 		})
 	}
 
+	var outletID *int
+	if req.OutletID > 0 {
+		outletID = &req.OutletID
+	}
+
 	var transactionID int
-	err = tx.QueryRow("INSERT INTO transactions (total_amount) VALUES ($1) RETURNING id", totalAmount).Scan(&transactionID)
+	var createdAt time.Time
+	err = tx.QueryRow(`INSERT INTO transactions (total_amount, outlet_id, customer_name, customer_phone)
+	                   VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')) RETURNING id, created_at`,
+		totalAmount, outletID, req.CustomerName, req.CustomerPhone).Scan(&transactionID, &createdAt)
 	if err != nil {
 		return nil, err
 	}
@@ -75,15 +155,15 @@ func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem
 	if len(details) > 0 {
 		// Prepare a single query for batch insert
 		valueStrings := make([]string, 0, len(details))
-		valueArgs := make([]interface{}, 0, len(details)*4)
+		valueArgs := make([]interface{}, 0, len(details)*5)
 
 		for i, detail := range details {
-			idx := i * 4
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d)", idx+1, idx+2, idx+3, idx+4))
-			valueArgs = append(valueArgs, transactionID, detail.ProductID, detail.Quantity, detail.Subtotal)
+			idx := i * 5
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", idx+1, idx+2, idx+3, idx+4, idx+5))
+			valueArgs = append(valueArgs, transactionID, detail.ProductID, detail.Quantity, detail.Subtotal, detail.CostPrice)
 		}
 
-		query := fmt.Sprintf("INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal) VALUES %s",
+		query := fmt.Sprintf("INSERT INTO transaction_details (transaction_id, product_id, quantity, subtotal, cost_price) VALUES %s",
 			strings.Join(valueStrings, ", "))
 
 		_, err = tx.Exec(query, valueArgs...)
@@ -92,34 +172,126 @@ func (repo *TransactionRepository) CreateTransaction(items []models.CheckoutItem
 		}
 	}
 
+	for _, detail := range details {
+		if len(detail.SerialNumbers) == 0 {
+			continue
+		}
+		if err := markSerialsSold(tx, transactionID, detail.ProductID, detail.SerialNumbers); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, u := range usages {
+		_, err = tx.Exec(`INSERT INTO transaction_component_usage (transaction_id, bundle_product_id, component_product_id, quantity, cost_price)
+		                  VALUES ($1, $2, $3, $4, $5)`, transactionID, u.bundleID, u.componentID, u.quantity, u.costPrice)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
 	return &models.Transaction{
-		ID:          transactionID,
-		TotalAmount: totalAmount,
-		Details:     details,
+		ID:            transactionID,
+		TotalAmount:   totalAmount,
+		OutletID:      outletID,
+		CustomerName:  req.CustomerName,
+		CustomerPhone: req.CustomerPhone,
+		CreatedAt:     createdAt,
+		Details:       details,
 	}, nil
 }
 
-func (repo *TransactionRepository) GetTransactionSummary(startDate, endDate string) (map[string]interface{}, error) {
-	summary := make(map[string]interface{})
+type componentStock struct {
+	productID   int
+	quantity    int
+	costPrice   int
+	stock       int
+	trackExpiry bool
+}
 
-	// Build query conditions based on date parameters
-	whereClause := "WHERE t.total_amount IS NOT NULL"
-	params := []interface{}{}
-	paramIndex := 1
+type componentUsage struct {
+	bundleID    int
+	componentID int
+	quantity    int
+	costPrice   int
+}
 
-	if startDate != "" {
-		whereClause += fmt.Sprintf(" AND DATE(t.created_at) >= $%d", paramIndex)
-		params = append(params, startDate)
-		paramIndex++
+// lockComponents - ambil resep produk komposit beserta stok komponennya; kosong untuk produk biasa
+func lockComponents(tx *sql.Tx, productID int, useLock bool) ([]componentStock, error) {
+	query := `SELECT pc.component_id, pc.quantity, p.cost_price, p.stock, p.track_expiry
+	          FROM product_components pc
+	          JOIN products p ON pc.component_id = p.id
+	          WHERE pc.product_id = $1
+	          ORDER BY pc.component_id`
+	if useLock {
+		query += " FOR UPDATE OF p"
 	}
-	if endDate != "" {
-		whereClause += fmt.Sprintf(" AND DATE(t.created_at) <= $%d", paramIndex)
-		params = append(params, endDate)
-		paramIndex++
+
+	rows, err := tx.Query(query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	components := make([]componentStock, 0)
+	for rows.Next() {
+		var c componentStock
+		if err := rows.Scan(&c.productID, &c.quantity, &c.costPrice, &c.stock, &c.trackExpiry); err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return components, nil
+}
+
+// deductStock - kurangi stok satu produk: stok outlet (kalau ada), batch FEFO (kalau track_expiry) dan total products.stock
+func deductStock(tx *sql.Tx, productID, stock int, trackExpiry bool, outletID, quantity int) error {
+	if outletID > 0 {
+		if err := deductLocationStock(tx, productID, outletID, quantity); err != nil {
+			return err
+		}
+	} else {
+		// Produk dengan stok per lokasi harus dijual dari outlet tertentu; kalau hanya products.stock
+		// yang dikurangi, jumlah stok lokasi jadi lebih besar dari total
+		var located bool
+		err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM product_stocks WHERE product_id = $1)", productID).Scan(&located)
+		if err != nil {
+			return err
+		}
+		if located {
+			return fmt.Errorf("outlet_id is required for product id %d: product has stock per location", productID)
+		}
+		if stock < quantity {
+			return fmt.Errorf("insufficient stock for product id %d: requested %d, available %d", productID, quantity, stock)
+		}
+	}
+
+	if trackExpiry {
+		if _, err := deductBatchesFEFO(tx, productID, outletID, quantity); err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec("UPDATE products SET stock = stock - $1 WHERE id = $2", quantity, productID)
+	return err
+}
+
+// GetTransactionSummary - ringkasan penjualan periode; bestLimit = jumlah produk terlaris yang dikembalikan
+func (repo *TransactionRepository) GetTransactionSummary(period models.ReportPeriod, bestLimit int) (*models.SalesSummary, error) {
+	whereClause, params := periodFilter(period)
+
+	summary := &models.SalesSummary{
+		Version:   models.SalesSummaryVersion,
+		StartDate: period.StartDate,
+		EndDate:   period.EndDate,
 	}
 
 	query := fmt.Sprintf(`
@@ -128,53 +300,176 @@ func (repo *TransactionRepository) GetTransactionSummary(startDate, endDate stri
 			COUNT(DISTINCT t.id) as total_transaction
 		FROM transactions t %s`, whereClause)
 
-	stmt, err := repo.db.Prepare(query)
+	err := repo.db.QueryRow(query, params...).Scan(&summary.TotalRevenue, &summary.TotalTransactions)
 	if err != nil {
 		return nil, err
 	}
-	defer stmt.Close()
 
-	var totalRevenue int64
-	var totalTransaction int
+	// Gross margin: HPP diambil dari snapshot cost_price di transaction_details
+	itemsQuery := fmt.Sprintf(`
+		SELECT COALESCE(SUM(td.quantity), 0), COALESCE(SUM(td.cost_price::bigint * td.quantity), 0)
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		%s`, whereClause)
 
-	err = stmt.QueryRow(params...).Scan(&totalRevenue, &totalTransaction)
+	err = repo.db.QueryRow(itemsQuery, params...).Scan(&summary.TotalItems, &summary.TotalCOGS)
 	if err != nil {
 		return nil, err
 	}
 
-	summary["total_revenue"] = totalRevenue
-	summary["total_transaction"] = totalTransaction
+	transactions := int64(summary.TotalTransactions)
+	summary.AverageBasketValue = models.Ratio(summary.TotalRevenue, transactions)
+	summary.ItemsPerTransaction = models.Ratio(summary.TotalItems, transactions)
+	summary.GrossProfit = summary.TotalRevenue - summary.TotalCOGS
+	summary.GrossMarginPercent = models.MarginPercent(summary.TotalRevenue, summary.GrossProfit)
+
+	summary.BestProducts, err = repo.getBestProducts(whereClause, params, bestLimit)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get best selling product
-	bestProductQuery := fmt.Sprintf(`
-		SELECT p.name, SUM(td.quantity) as total_quantity
+	summary.MarginByProduct, err = repo.getMargins(`p.id, p.name`, `JOIN products p ON td.product_id = p.id`, whereClause, params)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.MarginByCategory, err = repo.getMargins(`COALESCE(c.id, 0), COALESCE(c.name, 'Uncategorized')`,
+		`JOIN products p ON td.product_id = p.id
+		LEFT JOIN categories c ON p.category_id = c.id`, whereClause, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Penjualan paket sudah terhitung di margin_by_product; konsumsi komponennya dilaporkan terpisah
+	summary.ComponentConsumption, err = repo.getComponentConsumption(whereClause, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// getBestProducts - produk terlaris berdasarkan quantity
+func (repo *TransactionRepository) getBestProducts(whereClause string, params []interface{}, limit int) ([]models.BestProduct, error) {
+	query := fmt.Sprintf(`
+		SELECT p.id, p.name, SUM(td.quantity) as total_quantity, SUM(td.subtotal)::bigint
 		FROM transaction_details td
 		JOIN transactions t ON td.transaction_id = t.id
 		JOIN products p ON td.product_id = p.id
 		%s
 		GROUP BY p.id, p.name
-		ORDER BY total_quantity DESC
-		LIMIT 1`, whereClause)
+		ORDER BY total_quantity DESC, p.id
+		LIMIT %d`, whereClause, limit)
 
-	bestStmt, err := repo.db.Prepare(bestProductQuery)
+	rows, err := repo.db.Query(query, params...)
 	if err != nil {
 		return nil, err
 	}
-	defer bestStmt.Close()
+	defer rows.Close()
+
+	products := make([]models.BestProduct, 0)
+	for rows.Next() {
+		var p models.BestProduct
+		if err := rows.Scan(&p.ID, &p.Name, &p.Quantity, &p.Revenue); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
 
-	var bestProductName string
-	var bestProductQuantity int
-	err = bestStmt.QueryRow(params...).Scan(&bestProductName, &bestProductQuantity)
+// getComponentConsumption - jumlah komponen yang terpakai lewat penjualan produk komposit
+func (repo *TransactionRepository) getComponentConsumption(whereClause string, params []interface{}) ([]models.ComponentConsumption, error) {
+	query := fmt.Sprintf(`
+		SELECT p.id, p.name, SUM(u.quantity), SUM(u.cost_price::bigint * u.quantity)
+		FROM transaction_component_usage u
+		JOIN transactions t ON u.transaction_id = t.id
+		JOIN products p ON u.component_product_id = p.id
+		%s
+		GROUP BY p.id, p.name
+		ORDER BY SUM(u.quantity) DESC`, whereClause)
+
+	rows, err := repo.db.Query(query, params...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			// No transactions found, return empty best product
-			summary["best_products"] = map[string]interface{}{"name": "", "quantity": 0}
-		} else {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consumption := make([]models.ComponentConsumption, 0)
+	for rows.Next() {
+		var c models.ComponentConsumption
+		if err := rows.Scan(&c.ProductID, &c.Name, &c.Quantity, &c.COGS); err != nil {
 			return nil, err
 		}
-	} else {
-		summary["best_products"] = map[string]interface{}{"name": bestProductName, "quantity": bestProductQuantity}
+		consumption = append(consumption, c)
 	}
 
-	return summary, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return consumption, nil
+}
+
+// getMargins - pendapatan, HPP dan laba kotor dikelompokkan per key (produk atau kategori)
+func (repo *TransactionRepository) getMargins(groupKey, joins, whereClause string, params []interface{}) ([]models.MarginSummary, error) {
+	query := fmt.Sprintf(`
+		SELECT %s,
+			SUM(td.quantity) as total_quantity,
+			SUM(td.subtotal)::bigint as revenue,
+			SUM(td.cost_price::bigint * td.quantity) as cogs
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		%s
+		%s
+		GROUP BY 1, 2
+		ORDER BY SUM(td.subtotal) - SUM(td.cost_price::bigint * td.quantity) DESC, 1`, groupKey, joins, whereClause)
+
+	rows, err := repo.db.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	margins := make([]models.MarginSummary, 0)
+	for rows.Next() {
+		var m models.MarginSummary
+		err := rows.Scan(&m.ID, &m.Name, &m.Quantity, &m.Revenue, &m.COGS)
+		if err != nil {
+			return nil, err
+		}
+		m.GrossProfit = m.Revenue - m.COGS
+		m.MarginPercent = models.MarginPercent(m.Revenue, m.GrossProfit)
+		margins = append(margins, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return margins, nil
+}
+
+// GetTotals - total penjualan periode untuk dashboard (lebih ringan dari GetTransactionSummary)
+func (repo *TransactionRepository) GetTotals(period models.ReportPeriod) (models.DashboardTotals, error) {
+	whereClause, params := periodFilter(period)
+
+	totals := models.DashboardTotals{BusinessDate: period.StartDate}
+	query := fmt.Sprintf(`
+		SELECT COALESCE(SUM(t.total_amount), 0)::bigint, COUNT(*),
+		       COALESCE(SUM((SELECT SUM(td.quantity) FROM transaction_details td WHERE td.transaction_id = t.id)), 0)::bigint
+		FROM transactions t %s`, whereClause)
+
+	err := repo.db.QueryRow(query, params...).Scan(&totals.Revenue, &totals.Transactions, &totals.Items)
+	if err != nil {
+		return totals, err
+	}
+	totals.AverageBasketValue = models.Ratio(totals.Revenue, int64(totals.Transactions))
+
+	return totals, nil
 }
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"fmt"
+	"kasir/models"
+	"testing"
+	"time"
+)
+
+// TestTransactionRepositorySummaryMargins - query ringkasan (termasuk margin per produk dan kategori)
+// benar-benar dijalankan di Postgres, margin diurutkan dari laba kotor terbesar
+func TestTransactionRepositorySummaryMargins(t *testing.T) {
+	db := testDB(t)
+	products := NewProductRepository(db)
+	transactions := NewTransactionRepository(db)
+	prefix := fmt.Sprintf("test-%d-", time.Now().UnixNano())
+
+	var productIDs, transactionIDs []int
+	t.Cleanup(func() {
+		for _, id := range transactionIDs {
+			db.Exec("DELETE FROM transaction_details WHERE transaction_id = $1", id)
+			db.Exec("DELETE FROM transactions WHERE id = $1", id)
+		}
+		for _, id := range productIDs {
+			for _, table := range []string{"product_price_history", "product_cost_history", "stock_movements"} {
+				db.Exec("DELETE FROM "+table+" WHERE product_id = $1", id)
+			}
+			db.Exec("DELETE FROM products WHERE id = $1", id)
+		}
+	})
+
+	from := time.Now().Add(-time.Minute)
+	for _, p := range []models.Product{
+		{Name: prefix + "low margin", Price: 10000, CostPrice: 9000, Stock: 10},
+		{Name: prefix + "high margin", Price: 10000, CostPrice: 2000, Stock: 10},
+	} {
+		if err := products.Create(&p); err != nil {
+			t.Fatal(err)
+		}
+		productIDs = append(productIDs, p.ID)
+
+		trx, err := transactions.CreateTransaction(models.CheckoutRequest{
+			Items: []models.CheckoutItem{{ProductID: p.ID, Quantity: 2}},
+		}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		transactionIDs = append(transactionIDs, trx.ID)
+	}
+
+	summary, err := transactions.GetTransactionSummary(models.ReportPeriod{From: from, To: time.Now().Add(time.Minute)}, 5)
+	if err != nil {
+		t.Fatalf("GetTransactionSummary: %v", err)
+	}
+
+	var margins []models.MarginSummary
+	for _, m := range summary.MarginByProduct {
+		if m.ID == productIDs[0] || m.ID == productIDs[1] {
+			margins = append(margins, m)
+		}
+	}
+	if len(margins) != 2 {
+		t.Fatalf("margin_by_product has %d test products, want 2", len(margins))
+	}
+	if margins[0].ID != productIDs[1] {
+		t.Errorf("margin_by_product order = %d, %d, want highest gross profit first", margins[0].ID, margins[1].ID)
+	}
+	if margins[0].Revenue != 20000 || margins[0].COGS != 4000 || margins[0].GrossProfit != 16000 {
+		t.Errorf("high margin row = %+v, want revenue 20000, cogs 4000", margins[0])
+	}
+	if len(summary.MarginByCategory) == 0 {
+		t.Error("margin_by_category is empty")
+	}
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"kasir/models"
+	"kasir/repositories"
+)
+
+type BatchService struct {
+	repo *repositories.BatchRepository
+}
+
+func NewBatchService(repo *repositories.BatchRepository) *BatchService {
+	return &BatchService{repo: repo}
+}
+
+func (s *BatchService) GetExpiring(days int) ([]models.ProductBatch, error) {
+	return s.repo.GetExpiring(days)
+}
+
+func (s *BatchService) GetByProduct(productID int) ([]models.ProductBatch, error) {
+	return s.repo.GetByProduct(productID)
+}
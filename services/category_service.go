@@ -3,6 +3,7 @@ package services
 import (
 	"kasir/models"
 	"kasir/repositories"
+	"sort"
 )
 
 type CategoryService struct {
@@ -13,8 +14,45 @@ func NewCategoryService(repo *repositories.CategoryRepository) *CategoryService
 	return &CategoryService{repo: repo}
 }
 
-func (s *CategoryService) GetAll() ([]models.Category, error) {
-	return s.repo.GetAll()
+func (s *CategoryService) GetAll(includeArchived bool) ([]models.Category, error) {
+	return s.repo.GetAll(includeArchived)
+}
+
+// GetTree - kategori sebagai pohon; kategori yang parent-nya tidak ikut (mis. diarsipkan) jadi root
+func (s *CategoryService) GetTree(includeArchived bool) ([]models.Category, error) {
+	categories, err := s.repo.GetAll(includeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	byParent := make(map[int][]models.Category)
+	present := make(map[int]bool, len(categories))
+	for _, c := range categories {
+		present[c.ID] = true
+	}
+	for _, c := range categories {
+		parentID := 0
+		if c.ParentID != nil && present[*c.ParentID] {
+			parentID = *c.ParentID
+		}
+		byParent[parentID] = append(byParent[parentID], c)
+	}
+
+	var build func(parentID int) []models.Category
+	build = func(parentID int) []models.Category {
+		children := byParent[parentID]
+		sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+		for i := range children {
+			children[i].Children = build(children[i].ID)
+		}
+		return children
+	}
+
+	tree := build(0)
+	if tree == nil {
+		tree = make([]models.Category, 0)
+	}
+	return tree, nil
 }
 
 func (s *CategoryService) Create(data *models.Category) error {
@@ -25,10 +63,21 @@ func (s *CategoryService) GetByID(id int) (*models.Category, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *CategoryService) Update(category *models.Category) error {
-	return s.repo.Update(category)
+func (s *CategoryService) Update(id int, category models.CategoryUpdate) (*models.Category, error) {
+	if err := s.repo.Update(id, category); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(id)
+}
+
+func (s *CategoryService) Archive(id int, opts models.CategoryArchiveOptions) (int, error) {
+	return s.repo.Archive(id, opts)
+}
+
+func (s *CategoryService) Merge(sourceID, targetID int, dryRun bool) (*models.CategoryMergeResult, error) {
+	return s.repo.Merge(sourceID, targetID, dryRun)
 }
 
-func (s *CategoryService) Delete(id int) error {
-	return s.repo.Delete(id)
+func (s *CategoryService) Restore(id int) error {
+	return s.repo.Restore(id)
 }
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"kasir/models"
+	"kasir/repositories"
+)
+
+type LocationService struct {
+	repo *repositories.LocationRepository
+}
+
+func NewLocationService(repo *repositories.LocationRepository) *LocationService {
+	return &LocationService{repo: repo}
+}
+
+func (s *LocationService) GetAll() ([]models.Location, error) {
+	return s.repo.GetAll()
+}
+
+func (s *LocationService) Create(data *models.Location) error {
+	return s.repo.Create(data)
+}
+
+func (s *LocationService) GetByID(id int) (*models.Location, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *LocationService) Update(location *models.Location) error {
+	return s.repo.Update(location)
+}
+
+func (s *LocationService) GetStock(id int) ([]models.LocationStock, error) {
+	return s.repo.GetStock(id)
+}
+
+func (s *LocationService) SetStock(id int, stocks []models.LocationStock) error {
+	return s.repo.SetStock(id, stocks)
+}
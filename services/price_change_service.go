@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"kasir/models"
+	"kasir/repositories"
+	"log"
+	"time"
+)
+
+type PriceChangeService struct {
+	repo *repositories.PriceChangeRepository
+}
+
+func NewPriceChangeService(repo *repositories.PriceChangeRepository) *PriceChangeService {
+	return &PriceChangeService{repo: repo}
+}
+
+func (s *PriceChangeService) GetAll(status string, productID int) ([]models.ScheduledPriceChange, error) {
+	return s.repo.GetAll(status, productID)
+}
+
+func (s *PriceChangeService) Create(data *models.ScheduledPriceChange) error {
+	return s.repo.Create(data)
+}
+
+func (s *PriceChangeService) GetByID(id int) (*models.ScheduledPriceChange, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PriceChangeService) Cancel(id int) error {
+	return s.repo.Cancel(id)
+}
+
+// RunScheduler - background job yang menerapkan perubahan harga terjadwal setiap interval,
+// berhenti saat ctx dibatalkan
+func (s *PriceChangeService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := s.repo.ApplyDue(time.Now())
+		if err != nil {
+			log.Printf("Failed to apply scheduled price changes: %v", err)
+		} else if applied > 0 {
+			log.Printf("Applied %d scheduled price change(s)", applied)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"kasir/models"
+	"kasir/repositories"
+)
+
+type PriceListService struct {
+	repo *repositories.PriceListRepository
+}
+
+func NewPriceListService(repo *repositories.PriceListRepository) *PriceListService {
+	return &PriceListService{repo: repo}
+}
+
+func (s *PriceListService) GetAll() ([]models.PriceList, error) {
+	return s.repo.GetAll()
+}
+
+func (s *PriceListService) Create(data *models.PriceList) error {
+	return s.repo.Create(data)
+}
+
+func (s *PriceListService) GetByID(id int) (*models.PriceList, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PriceListService) Update(priceList *models.PriceList) error {
+	return s.repo.Update(priceList)
+}
+
+func (s *PriceListService) Delete(id int) error {
+	return s.repo.Delete(id)
+}
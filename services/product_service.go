@@ -1,34 +1,368 @@
 package services
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"kasir/media"
 	"kasir/models"
 	"kasir/repositories"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	thumbnailSize  = 320
+	maxImagePixels = 40_000_000 // tolak gambar raksasa sebelum di-decode penuh
 )
 
 type ProductService struct {
-	repo *repositories.ProductRepository
+	repo  *repositories.ProductRepository
+	store media.Storage
 }
 
-func NewProductService(repo *repositories.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+func NewProductService(repo *repositories.ProductRepository, store media.Storage) *ProductService {
+	return &ProductService{repo: repo, store: store}
 }
 
-func (s *ProductService) GetAll(name string) ([]models.Product, error) {
-	return s.repo.GetAll(name)
+func (s *ProductService) GetAll(filter models.ProductFilter) (*models.ProductPage, error) {
+	page, err := s.repo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Data {
+		s.setImageURLs(&page.Data[i])
+	}
+	return page, nil
 }
 
 func (s *ProductService) Create(data *models.Product) error {
 	return s.repo.Create(data)
 }
 
-func (s *ProductService) GetByID(id int) (*models.Product, error) {
-	return s.repo.GetByID(id)
+func (s *ProductService) GetByID(id int, pc models.PriceContext) (*models.Product, error) {
+	product, err := s.repo.GetByID(id, pc)
+	if err != nil {
+		return nil, err
+	}
+	s.setImageURLs(product)
+	return product, nil
+}
+
+// Update - ubah produk lalu kembalikan data tersimpan (termasuk field yang tidak dikirim)
+func (s *ProductService) Update(id int, product models.ProductUpdate) (*models.Product, error) {
+	if err := s.repo.Update(id, product); err != nil {
+		return nil, err
+	}
+	return s.GetByID(id, models.PriceContext{})
+}
+
+func (s *ProductService) Archive(id int) error {
+	return s.repo.Archive(id)
+}
+
+func (s *ProductService) Restore(id int) error {
+	return s.repo.Restore(id)
+}
+
+func (s *ProductService) Search(q string, limit int) ([]models.ProductSearchHit, error) {
+	hits, err := s.repo.Search(q, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hits {
+		s.setImageURLs(&hits[i].Product)
+	}
+	return hits, nil
+}
+
+func (s *ProductService) GetPurchaseHistory(id int) ([]models.PurchaseHistory, error) {
+	return s.repo.GetPurchaseHistory(id)
+}
+
+func (s *ProductService) GetPriceHistory(id int) ([]models.PriceHistory, error) {
+	return s.repo.GetPriceHistory(id)
+}
+
+func (s *ProductService) GetComponents(id int) ([]models.ProductComponent, error) {
+	return s.repo.GetComponents(id)
+}
+
+func (s *ProductService) SetComponents(id int, components []models.ProductComponent) error {
+	return s.repo.SetComponents(id, components)
+}
+
+func (s *ProductService) GetLowStock() ([]models.Product, error) {
+	products, err := s.repo.GetLowStock()
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		s.setImageURLs(&products[i])
+	}
+	return products, nil
+}
+
+func (s *ProductService) GetReorderSuggestions(days, coverDays int) ([]models.ReorderSupplierGroup, error) {
+	return s.repo.GetReorderSuggestions(days, coverDays)
+}
+
+// SetImage - simpan foto produk (JPEG/PNG/GIF) beserta thumbnail JPEG-nya, foto lama dihapus
+func (s *ProductService) SetImage(id int, data []byte) (*models.Product, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("invalid image: supported formats are jpeg, png and gif")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("invalid image: %dx%d is too large", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid image: %v", err)
+	}
+
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, media.Thumbnail(img, thumbnailSize), &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	ext := format
+	if ext == "jpeg" {
+		ext = "jpg"
+	}
+	key := fmt.Sprintf("products/%d/%s.%s", id, hex.EncodeToString(suffix), ext)
+
+	if err := s.store.Save(key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.store.Save(thumbnailKey(key), &thumb); err != nil {
+		s.deleteImageFiles(key)
+		return nil, err
+	}
+
+	oldKey, err := s.repo.SetImageKey(id, key)
+	if err != nil {
+		s.deleteImageFiles(key)
+		return nil, err
+	}
+	if oldKey != "" {
+		s.deleteImageFiles(oldKey)
+	}
+
+	return s.GetByID(id, models.PriceContext{})
+}
+
+// DeleteImage - hapus foto produk
+func (s *ProductService) DeleteImage(id int) error {
+	oldKey, err := s.repo.SetImageKey(id, "")
+	if err != nil {
+		return err
+	}
+	if oldKey != "" {
+		s.deleteImageFiles(oldKey)
+	}
+	return nil
+}
+
+func (s *ProductService) setImageURLs(p *models.Product) {
+	if p.ImageKey == "" {
+		return
+	}
+	p.ImageURL = s.store.URL(p.ImageKey)
+	p.ThumbnailURL = s.store.URL(thumbnailKey(p.ImageKey))
+}
+
+// deleteImageFiles - hapus foto dan thumbnail; gagal hapus cukup dicatat karena data produk sudah konsisten
+func (s *ProductService) deleteImageFiles(key string) {
+	for _, k := range []string{key, thumbnailKey(key)} {
+		if err := s.store.Delete(k); err != nil {
+			log.Printf("failed to delete media %s: %v", k, err)
+		}
+	}
+}
+
+// thumbnailKey - "products/1/ab.png" -> "products/1/ab_thumb.jpg"
+func thumbnailKey(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "_thumb.jpg"
+}
+
+func (s *ProductService) BulkUpdate(req models.BulkUpdateRequest, dryRun bool) (*models.BulkUpdateResult, error) {
+	return s.repo.BulkUpdate(req, dryRun)
+}
+
+// Import - validasi file import (baris pertama header) lalu upsert produk by SKU.
+// Error format per baris dikumpulkan dulu; kalau ada, repository tetap dijalankan sebagai dry run
+// supaya report juga memuat error yang butuh data (mis. produk baru tanpa harga).
+func (s *ProductService) Import(records [][]string, dryRun bool) (*models.ProductImportReport, error) {
+	rows, parseErrors := parseProductImport(records)
+
+	report, err := s.repo.Import(rows, dryRun || len(parseErrors) > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	report.DryRun = dryRun
+	report.TotalRows += countInvalidRows(parseErrors)
+	report.Errors = append(parseErrors, report.Errors...)
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+
+	return report, nil
+}
+
+// ExportCatalogue - katalog produk sebagai baris (header + data) dengan kolom yang sama dengan file import
+func (s *ProductService) ExportCatalogue() ([][]interface{}, error) {
+	products, err := s.repo.GetCatalogue()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([][]interface{}, 0, len(products)+1)
+	header := make([]interface{}, len(models.ProductImportColumns))
+	for i, col := range models.ProductImportColumns {
+		header[i] = col
+	}
+	rows = append(rows, header)
+
+	for _, p := range products {
+		category := ""
+		if p.Category != nil {
+			category = p.Category.Name
+		}
+		rows = append(rows, []interface{}{
+			p.SKU, p.Name, category, p.Price, p.CostPrice, p.Stock, p.MinStock, p.ReorderQty, strconv.FormatBool(p.TrackExpiry),
+		})
+	}
+
+	return rows, nil
+}
+
+func parseProductImport(records [][]string) ([]models.ProductImportRow, []models.ProductImportError) {
+	errs := make([]models.ProductImportError, 0)
+	if len(records) == 0 {
+		return nil, append(errs, models.ProductImportError{Message: "file is empty"})
+	}
+
+	// Header dicocokkan by nama (case-insensitive), urutan kolom bebas
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"sku", "name"} {
+		if _, ok := columns[required]; !ok {
+			errs = append(errs, models.ProductImportError{Row: 1, Field: required, Message: fmt.Sprintf("missing required column %q", required)})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	cell := func(record []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := make([]models.ProductImportRow, 0, len(records)-1)
+	seen := make(map[string]int)
+	for i, record := range records[1:] {
+		rowNum := i + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := models.ProductImportRow{
+			Row:      rowNum,
+			SKU:      cell(record, "sku"),
+			Name:     cell(record, "name"),
+			Category: cell(record, "category"),
+		}
+
+		rowErrors := make([]models.ProductImportError, 0)
+		addError := func(field, msg string) {
+			rowErrors = append(rowErrors, models.ProductImportError{Row: rowNum, SKU: row.SKU, Field: field, Message: msg})
+		}
+
+		if row.SKU == "" {
+			addError("sku", "sku is required")
+		} else if first, ok := seen[strings.ToLower(row.SKU)]; ok {
+			addError("sku", fmt.Sprintf("duplicate sku, first used in row %d", first))
+		} else {
+			seen[strings.ToLower(row.SKU)] = rowNum
+		}
+
+		for _, field := range []struct {
+			column string
+			dest   **int
+		}{
+			{"price", &row.Price},
+			{"cost_price", &row.CostPrice},
+			{"stock", &row.Stock},
+			{"min_stock", &row.MinStock},
+			{"reorder_qty", &row.ReorderQty},
+		} {
+			value := cell(record, field.column)
+			if value == "" {
+				continue
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				addError(field.column, fmt.Sprintf("invalid %s: %q", field.column, value))
+				continue
+			}
+			*field.dest = &n
+		}
+
+		if value := cell(record, "track_expiry"); value != "" {
+			b, err := strconv.ParseBool(strings.ToLower(value))
+			if err != nil {
+				addError("track_expiry", fmt.Sprintf("invalid track_expiry: %q", value))
+			} else {
+				row.TrackExpiry = &b
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			errs = append(errs, rowErrors...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, errs
 }
 
-func (s *ProductService) Update(product *models.Product) error {
-	return s.repo.Update(product)
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
 }
 
-func (s *ProductService) Delete(id int) error {
-	return s.repo.Delete(id)
+// countInvalidRows - jumlah baris data yang ditolak saat parsing (tidak sampai ke repository)
+func countInvalidRows(errs []models.ProductImportError) int {
+	rows := make(map[int]bool)
+	for _, e := range errs {
+		if e.Row > 1 {
+			rows[e.Row] = true
+		}
+	}
+	return len(rows)
 }
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"kasir/models"
+	"reflect"
+	"testing"
+)
+
+func intPtr(n int) *int    { return &n }
+func boolPtr(b bool) *bool { return &b }
+
+func TestParseProductImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		records  [][]string
+		wantRows []models.ProductImportRow
+		wantErrs []models.ProductImportError
+	}{
+		{
+			name:     "empty file",
+			records:  nil,
+			wantErrs: []models.ProductImportError{{Message: "file is empty"}},
+		},
+		{
+			name:    "missing required columns",
+			records: [][]string{{"Price", "Stock"}},
+			wantErrs: []models.ProductImportError{
+				{Row: 1, Field: "sku", Message: `missing required column "sku"`},
+				{Row: 1, Field: "name", Message: `missing required column "name"`},
+			},
+		},
+		{
+			name: "all columns, any order and case",
+			records: [][]string{
+				{"Track_Expiry", " NAME ", "sku", "category", "price", "cost_price", "stock", "min_stock", "reorder_qty"},
+				{"TRUE", " Susu UHT ", "SKU-1", "Minuman", "7000", "5500", "24", "6", "48"},
+			},
+			wantRows: []models.ProductImportRow{{
+				Row: 2, SKU: "SKU-1", Name: "Susu UHT", Category: "Minuman",
+				Price: intPtr(7000), CostPrice: intPtr(5500), Stock: intPtr(24), MinStock: intPtr(6), ReorderQty: intPtr(48),
+				TrackExpiry: boolPtr(true),
+			}},
+		},
+		{
+			name: "empty cells keep nil, blank and short records",
+			records: [][]string{
+				{"sku", "name", "price", "stock"},
+				{"SKU-1", "", "", ""},
+				{"", " ", ""},
+				{"SKU-2"},
+			},
+			wantRows: []models.ProductImportRow{{Row: 2, SKU: "SKU-1"}, {Row: 4, SKU: "SKU-2"}},
+		},
+		{
+			name: "row errors are reported and the row is skipped",
+			records: [][]string{
+				{"sku", "name", "price", "stock", "track_expiry"},
+				{"", "No SKU", "1000", "", ""},
+				{"SKU-1", "Kopi", "-5", "abc", "maybe"},
+				{"SKU-2", "Teh", "3000", "", ""},
+				{"sku-2", "Teh Lagi", "3000", "", ""},
+			},
+			wantRows: []models.ProductImportRow{{Row: 4, SKU: "SKU-2", Name: "Teh", Price: intPtr(3000)}},
+			wantErrs: []models.ProductImportError{
+				{Row: 2, Field: "sku", Message: "sku is required"},
+				{Row: 3, SKU: "SKU-1", Field: "price", Message: `invalid price: "-5"`},
+				{Row: 3, SKU: "SKU-1", Field: "stock", Message: `invalid stock: "abc"`},
+				{Row: 3, SKU: "SKU-1", Field: "track_expiry", Message: `invalid track_expiry: "maybe"`},
+				{Row: 5, SKU: "sku-2", Field: "sku", Message: "duplicate sku, first used in row 4"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, errs := parseProductImport(tt.records)
+			if len(rows) != 0 || len(tt.wantRows) != 0 {
+				if !reflect.DeepEqual(rows, tt.wantRows) {
+					t.Errorf("rows = %+v, want %+v", rows, tt.wantRows)
+				}
+			}
+			if len(errs) != 0 || len(tt.wantErrs) != 0 {
+				if !reflect.DeepEqual(errs, tt.wantErrs) {
+					t.Errorf("errors = %+v, want %+v", errs, tt.wantErrs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"kasir/models"
+	"kasir/repositories"
+)
+
+type PurchaseOrderService struct {
+	repo *repositories.PurchaseOrderRepository
+}
+
+func NewPurchaseOrderService(repo *repositories.PurchaseOrderRepository) *PurchaseOrderService {
+	return &PurchaseOrderService{repo: repo}
+}
+
+func (s *PurchaseOrderService) GetAll(status string) ([]models.PurchaseOrder, error) {
+	return s.repo.GetAll(status)
+}
+
+func (s *PurchaseOrderService) Create(data *models.PurchaseOrder) error {
+	return s.repo.Create(data)
+}
+
+func (s *PurchaseOrderService) GetByID(id int) (*models.PurchaseOrder, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *PurchaseOrderService) Update(po *models.PurchaseOrder) error {
+	return s.repo.Update(po)
+}
+
+func (s *PurchaseOrderService) Send(id int) error {
+	return s.repo.UpdateStatus(id, models.PurchaseOrderSent)
+}
+
+func (s *PurchaseOrderService) Cancel(id int) error {
+	return s.repo.UpdateStatus(id, models.PurchaseOrderCancelled)
+}
+
+func (s *PurchaseOrderService) Receive(id int, req models.ReceiveRequest) (*models.GoodsReceipt, error) {
+	return s.repo.Receive(id, req)
+}
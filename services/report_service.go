@@ -0,0 +1,111 @@
+package services
+
+import (
+	"kasir/models"
+	"kasir/repositories"
+	"sort"
+	"time"
+)
+
+type ReportService struct {
+	repo     *repositories.ReportRepository
+	calendar *models.BusinessCalendar
+}
+
+func NewReportService(repo *repositories.ReportRepository, calendar *models.BusinessCalendar) *ReportService {
+	return &ReportService{repo: repo, calendar: calendar}
+}
+
+// GetSalesBreakdown - penjualan per kelompok beserta top-N dan bottom-N berdasarkan revenue
+func (s *ReportService) GetSalesBreakdown(groupBy, startDate, endDate string, limit int) (*models.SalesBreakdown, error) {
+	period, err := s.calendar.Period(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.GetSalesBreakdown(groupBy, period)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]models.SalesBreakdownRow, len(rows))
+	copy(ranked, rows)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Revenue > ranked[j].Revenue })
+
+	n := min(limit, len(ranked))
+	bottom := make([]models.SalesBreakdownRow, 0, n)
+	for i := len(ranked) - 1; i >= len(ranked)-n; i-- {
+		bottom = append(bottom, ranked[i])
+	}
+
+	return &models.SalesBreakdown{
+		GroupBy:   groupBy,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Rows:      rows,
+		Top:       ranked[:n],
+		Bottom:    bottom,
+	}, nil
+}
+
+// GetZReport - Z-report satu hari bisnis (YYYY-MM-DD); tanggal kosong berarti hari bisnis yang sedang berjalan
+func (s *ReportService) GetZReport(date string, outletID int) (*models.ZReport, error) {
+	if date == "" {
+		date = s.calendar.BusinessDate(time.Now()).Format("2006-01-02")
+	}
+	day, err := s.calendar.ParseDate("date", date)
+	if err != nil {
+		return nil, err
+	}
+
+	period := s.calendar.DayPeriod(day)
+	period.OutletID = outletID
+
+	report, err := s.repo.GetZReport(period)
+	if err != nil {
+		return nil, err
+	}
+	report.BusinessDate = date
+
+	return report, nil
+}
+
+// Period - validasi start_date/end_date menjadi periode laporan, dibatasi ke satu outlet kalau outletID > 0
+func (s *ReportService) Period(startDate, endDate string, outletID int) (models.ReportPeriod, error) {
+	period, err := s.calendar.Period(startDate, endDate)
+	if err != nil {
+		return period, err
+	}
+	period.OutletID = outletID
+	return period, nil
+}
+
+// EachTransactionLine - item transaksi dalam periode, dikirim satu per satu ke fn
+func (s *ReportService) EachTransactionLine(period models.ReportPeriod, fn func(models.TransactionLine) error) error {
+	return s.repo.EachTransactionLine(period, fn)
+}
+
+// GetInventoryValuation - nilai persediaan dengan metode average|fifo (default average).
+// asOfDate (YYYY-MM-DD) = stok pada akhir hari bisnis tersebut; kosong = stok saat ini.
+func (s *ReportService) GetInventoryValuation(method, asOfDate string) (*models.InventoryValuation, error) {
+	if method == "" {
+		method = models.ValuationAverage
+	}
+
+	var asOf time.Time
+	if asOfDate != "" {
+		date, err := s.calendar.ParseDate("as_of", asOfDate)
+		if err != nil {
+			return nil, err
+		}
+		asOf = s.calendar.DayStart(date.AddDate(0, 0, 1))
+	}
+
+	valuation, err := s.repo.GetInventoryValuation(method, asOf)
+	if err != nil {
+		return nil, err
+	}
+	valuation.AsOf = asOfDate
+
+	return valuation, nil
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"kasir/models"
+	"kasir/repositories"
+)
+
+type SerialService struct {
+	repo *repositories.SerialRepository
+}
+
+func NewSerialService(repo *repositories.SerialRepository) *SerialService {
+	return &SerialService{repo: repo}
+}
+
+func (s *SerialService) GetBySerialNumber(serialNumber string) ([]models.ProductSerial, error) {
+	return s.repo.GetBySerialNumber(serialNumber)
+}
+
+func (s *SerialService) GetByProduct(productID int, status string) ([]models.ProductSerial, error) {
+	return s.repo.GetByProduct(productID, status)
+}
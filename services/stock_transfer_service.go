@@ -0,0 +1,38 @@
+package services
+
+import (
+	"kasir/models"
+	"kasir/repositories"
+)
+
+type StockTransferService struct {
+	repo *repositories.StockTransferRepository
+}
+
+func NewStockTransferService(repo *repositories.StockTransferRepository) *StockTransferService {
+	return &StockTransferService{repo: repo}
+}
+
+func (s *StockTransferService) GetAll(status string) ([]models.StockTransfer, error) {
+	return s.repo.GetAll(status)
+}
+
+func (s *StockTransferService) Create(data *models.StockTransfer) error {
+	return s.repo.Create(data)
+}
+
+func (s *StockTransferService) GetByID(id int) (*models.StockTransfer, error) {
+	return s.repo.GetByID(id)
+}
+
+func (s *StockTransferService) Ship(id int) error {
+	return s.repo.Ship(id)
+}
+
+func (s *StockTransferService) Receive(id int) error {
+	return s.repo.Receive(id)
+}
+
+func (s *StockTransferService) Cancel(id int) error {
+	return s.repo.Cancel(id)
+}
@@ -1,22 +1,153 @@
 package services
 
 import (
+	"kasir/events"
 	"kasir/models"
 	"kasir/repositories"
+	"log"
+	"sort"
+	"time"
 )
 
 type TransactionService struct {
-	repo *repositories.TransactionRepository
+	repo     *repositories.TransactionRepository
+	calendar *models.BusinessCalendar
+	bus      *events.Bus
 }
 
-func NewTransactionService(repo *repositories.TransactionRepository) *TransactionService {
-	return &TransactionService{repo: repo}
+func NewTransactionService(repo *repositories.TransactionRepository, calendar *models.BusinessCalendar, bus *events.Bus) *TransactionService {
+	return &TransactionService{repo: repo, calendar: calendar, bus: bus}
 }
 
-func (s *TransactionService) Checkout(items []models.CheckoutItem, useLock bool) (*models.Transaction, error) {
-	return s.repo.CreateTransaction(items, useLock)
+func (s *TransactionService) Checkout(req models.CheckoutRequest, useLock bool) (*models.Transaction, error) {
+	transaction, err := s.repo.CreateTransaction(req, useLock)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishSale(transaction)
+	return transaction, nil
+}
+
+// publishSale - kirim event sale ke dashboard setelah transaksi di-commit.
+// Tanpa dashboard yang terhubung, query total dilewati supaya checkout tidak bertambah lambat.
+// Gagal menghitung total tidak membatalkan checkout, event hanya dilewati.
+func (s *TransactionService) publishSale(transaction *models.Transaction) {
+	if !s.bus.HasSubscribers() {
+		return
+	}
+
+	totals, err := s.DailyTotals(transaction.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to compute dashboard totals for transaction %d: %v", transaction.ID, err)
+		return
+	}
+
+	s.bus.Publish(models.DashboardEvent{
+		Type:        models.DashboardEventSale,
+		Transaction: transaction,
+		Totals:      totals,
+		Time:        transaction.CreatedAt,
+	})
+}
+
+// DailyTotals - total berjalan hari bisnis tempat waktu t jatuh
+func (s *TransactionService) DailyTotals(t time.Time) (models.DashboardTotals, error) {
+	return s.repo.GetTotals(s.calendar.DayPeriod(s.calendar.BusinessDate(t)))
+}
+
+// SubscribeDashboard - langganan event penjualan untuk dashboard
+func (s *TransactionService) SubscribeDashboard() (<-chan models.DashboardEvent, func()) {
+	return s.bus.Subscribe()
 }
 
-func (s *TransactionService) GetTransactionSummary(startDate, endDate string) (map[string]interface{}, error) {
-	return s.repo.GetTransactionSummary(startDate, endDate)
+func (s *TransactionService) GetTransactionSummary(startDate, endDate string, bestLimit int) (*models.SalesSummary, error) {
+	period, err := s.calendar.Period(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetTransactionSummary(period, bestLimit)
+}
+
+// CompareTransactionSummary - ringkasan periode sekarang dibandingkan periode sebelumnya atau tahun lalu
+func (s *TransactionService) CompareTransactionSummary(startDate, endDate, mode string) (*models.SalesComparison, error) {
+	current, err := s.calendar.Period(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.calendar.ComparisonPeriod(current, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := s.repo.GetTransactionSummary(current, 0)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := s.repo.GetTransactionSummary(previous, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SalesComparison{
+		Compare:  mode,
+		Current:  models.ReportPeriodRange{StartDate: current.StartDate, EndDate: current.EndDate},
+		Previous: models.ReportPeriodRange{StartDate: previous.StartDate, EndDate: previous.EndDate},
+		Metrics: models.SalesSummaryDeltas{
+			TotalRevenue:        models.NewDelta(float64(cur.TotalRevenue), float64(prev.TotalRevenue)),
+			TotalTransactions:   models.NewDelta(float64(cur.TotalTransactions), float64(prev.TotalTransactions)),
+			TotalItems:          models.NewDelta(float64(cur.TotalItems), float64(prev.TotalItems)),
+			AverageBasketValue:  models.NewDelta(cur.AverageBasketValue, prev.AverageBasketValue),
+			ItemsPerTransaction: models.NewDelta(cur.ItemsPerTransaction, prev.ItemsPerTransaction),
+			TotalCOGS:           models.NewDelta(float64(cur.TotalCOGS), float64(prev.TotalCOGS)),
+			GrossProfit:         models.NewDelta(float64(cur.GrossProfit), float64(prev.GrossProfit)),
+			GrossMarginPercent:  models.NewDelta(cur.GrossMarginPercent, prev.GrossMarginPercent),
+		},
+		ByCategory: compareMargins(cur.MarginByCategory, prev.MarginByCategory),
+		ByProduct:  compareMargins(cur.MarginByProduct, prev.MarginByProduct),
+	}, nil
+}
+
+// compareMargins - gabungkan margin dua periode per id; yang hanya ada di salah satu periode dianggap nol di periode lain.
+// Urut revenue periode sekarang, lalu revenue pembanding, lalu nama.
+func compareMargins(current, previous []models.MarginSummary) []models.ComparisonRow {
+	type pair struct{ cur, prev models.MarginSummary }
+	byID := make(map[int]*pair)
+	order := make([]int, 0, len(current)+len(previous))
+	for _, m := range current {
+		byID[m.ID] = &pair{cur: m}
+		order = append(order, m.ID)
+	}
+	for _, m := range previous {
+		p, ok := byID[m.ID]
+		if !ok {
+			p = &pair{cur: models.MarginSummary{ID: m.ID, Name: m.Name}}
+			byID[m.ID] = p
+			order = append(order, m.ID)
+		}
+		p.prev = m
+	}
+
+	rows := make([]models.ComparisonRow, 0, len(order))
+	for _, id := range order {
+		p := byID[id]
+		rows = append(rows, models.ComparisonRow{
+			ID:          id,
+			Name:        p.cur.Name,
+			Quantity:    models.NewDelta(float64(p.cur.Quantity), float64(p.prev.Quantity)),
+			Revenue:     models.NewDelta(float64(p.cur.Revenue), float64(p.prev.Revenue)),
+			GrossProfit: models.NewDelta(float64(p.cur.GrossProfit), float64(p.prev.GrossProfit)),
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Revenue.Current != rows[j].Revenue.Current {
+			return rows[i].Revenue.Current > rows[j].Revenue.Current
+		}
+		if rows[i].Revenue.Previous != rows[j].Revenue.Previous {
+			return rows[i].Revenue.Previous > rows[j].Revenue.Previous
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	return rows
 }